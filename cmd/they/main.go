@@ -1,11 +1,19 @@
 package main
 
 import (
-	"fmt"
+	"flag"
+	"log"
+	"os"
 
 	"github.com/clfs/they/internal/engine"
 )
 
 func main() {
-	fmt.Println(engine.Banner)
+	banner := flag.Bool("banner", false, "print a banner on startup")
+	flag.Parse()
+
+	e := engine.NewWithOptions(engine.Options{Banner: *banner})
+	if err := e.Run(os.Stdin, os.Stdout); err != nil {
+		log.Fatal(err)
+	}
 }
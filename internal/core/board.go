@@ -1,5 +1,7 @@
 package core
 
+import "math/bits"
+
 // Board represents the positions of pieces on a board.
 type Board struct {
 	// Squares occupied by white pieces.
@@ -10,6 +12,14 @@ type Board struct {
 
 	// Piece occupancy bitboards, indexed by [PieceType].
 	pieces [6]Bitboard
+
+	// The material of each player in centipawns, indexed by 0 for White and 1
+	// for Black. It is kept up to date as pieces are set and cleared.
+	material [2]int
+
+	// The Zobrist hash of the pieces on the board. It is kept up to date as
+	// pieces are set and cleared.
+	key Key
 }
 
 // NewBoard returns a new [Board] with pieces in the starting position.
@@ -55,6 +65,34 @@ func (b *Board) Black() Bitboard {
 	return b.black
 }
 
+// Pieces returns all squares occupied by p.
+func (b *Board) Pieces(p Piece) Bitboard {
+	return b.pieces[p.PieceType] & b.color(p.Color)
+}
+
+// color returns all squares occupied by pieces of color c.
+func (b *Board) color(c Color) Bitboard {
+	if c == White {
+		return b.white
+	}
+	return b.black
+}
+
+// Occupied returns all occupied squares.
+func (b *Board) Occupied() Bitboard {
+	return b.white | b.black
+}
+
+// KingSquare returns the square of the king of color c, if any. Boards set up
+// by hand, like in tests, may lack a king.
+func (b *Board) KingSquare(c Color) (Square, bool) {
+	k := b.Pieces(NewPiece(c, King))
+	if k.IsEmpty() {
+		return 0, false
+	}
+	return Square(bits.TrailingZeros64(uint64(k))), true
+}
+
 // PieceColor returns the color of the piece on s, if any.
 func (b *Board) PieceColor(s Square) (Color, bool) {
 	switch {
@@ -103,6 +141,8 @@ func (b *Board) Set(p Piece, s Square) {
 		b.black.Set(s)
 	}
 	b.pieces[p.PieceType].Set(s)
+	b.material[colorIndex(p.Color)] += p.PieceType.material()
+	b.key ^= Key(polyglotPieceKey(p, s))
 }
 
 // Move moves a piece between two squares.
@@ -115,14 +155,24 @@ func (b *Board) Move(p Piece, from, to Square) {
 //
 // If the square is already empty, nothing happens.
 func (b *Board) Clear(s Square) {
+	p, ok := b.Piece(s)
+	if !ok {
+		return
+	}
 	b.white.Clear(s)
 	b.black.Clear(s)
-	for i := range b.pieces {
-		b.pieces[i].Clear(s)
-	}
+	b.pieces[p.PieceType].Clear(s)
+	b.material[colorIndex(p.Color)] -= p.PieceType.material()
+	b.key ^= Key(polyglotPieceKey(p, s))
 }
 
 // IsOccupied returns true if the given square is occupied.
 func (b *Board) IsOccupied(s Square) bool {
 	return b.white.Get(s) || b.black.Get(s)
 }
+
+// IsEmpty returns true if the given square is empty.
+func (b *Board) IsEmpty(s Square) bool {
+	occupied := b.Occupied()
+	return !occupied.Get(s)
+}
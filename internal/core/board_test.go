@@ -0,0 +1,40 @@
+package core
+
+import "testing"
+
+func TestBoard_IsEmpty(t *testing.T) {
+	b := NewBoard()
+
+	for s := A1; s <= H8; s++ {
+		want := s.Rank() >= Rank3 && s.Rank() <= Rank6
+		if got := b.IsEmpty(s); got != want {
+			t.Errorf("IsEmpty(%v): got %v, want %v", s, got, want)
+		}
+		if got := b.IsOccupied(s); got == want {
+			t.Errorf("IsOccupied(%v): got %v, want %v", s, got, !want)
+		}
+	}
+
+	if got, want := b.Occupied(), b.White()|b.Black(); got != want {
+		t.Errorf("Occupied(): got %#x, want %#x", uint64(got), uint64(want))
+	}
+}
+
+func TestBoard_KingSquare(t *testing.T) {
+	b := NewBoard()
+	if got, ok := b.KingSquare(White); !ok || got != E1 {
+		t.Errorf("KingSquare(White): got %v, %t, want %v, true", got, ok, E1)
+	}
+	if got, ok := b.KingSquare(Black); !ok || got != E8 {
+		t.Errorf("KingSquare(Black): got %v, %t, want %v, true", got, ok, E8)
+	}
+
+	// Boards set up by hand may lack a king.
+	b.Clear(E8)
+	if got, ok := b.KingSquare(Black); ok {
+		t.Errorf("KingSquare(Black) without a black king: got %v, want none", got)
+	}
+	if got, ok := b.KingSquare(White); !ok || got != E1 {
+		t.Errorf("KingSquare(White) without a black king: got %v, %t, want %v, true", got, ok, E1)
+	}
+}
@@ -4,6 +4,7 @@ package core
 import (
 	"fmt"
 	"math/bits"
+	"strings"
 )
 
 // A Bitboard stores one bit of information per board square.
@@ -35,6 +36,75 @@ func (b *Bitboard) Clear(s Square) {
 	*b &^= s.Bitboard()
 }
 
+// Next clears the lowest set bit of b and returns its square, or false if b is
+// empty. Calling Next until it returns false visits the set squares from A1 to
+// H8.
+func (b *Bitboard) Next() (Square, bool) {
+	if *b == 0 {
+		return 0, false
+	}
+	s := Square(bits.TrailingZeros64(uint64(*b)))
+	*b &= *b - 1
+	return s, true
+}
+
+// All yields the set squares of b from A1 to H8. Use it with range:
+//
+//	for s := range b.All {
+//		...
+//	}
+func (b Bitboard) All(yield func(Square) bool) {
+	for ; b != 0; b &= b - 1 {
+		if !yield(Square(bits.TrailingZeros64(uint64(b)))) {
+			return
+		}
+	}
+}
+
+// North returns b with every bit moved one square toward rank 8. Bits on rank 8
+// are dropped.
+func (b Bitboard) North() Bitboard {
+	return b << 8
+}
+
+// South returns b with every bit moved one square toward rank 1. Bits on rank 1
+// are dropped.
+func (b Bitboard) South() Bitboard {
+	return b >> 8
+}
+
+// East returns b with every bit moved one square toward the H-file. Bits on
+// the H-file are dropped rather than wrapping around to the A-file.
+func (b Bitboard) East() Bitboard {
+	return (b &^ fileBitboards[FileH]) << 1
+}
+
+// West returns b with every bit moved one square toward the A-file. Bits on
+// the A-file are dropped rather than wrapping around to the H-file.
+func (b Bitboard) West() Bitboard {
+	return (b &^ fileBitboards[FileA]) >> 1
+}
+
+// NorthEast returns b with every bit moved one square diagonally toward H8.
+func (b Bitboard) NorthEast() Bitboard {
+	return (b &^ fileBitboards[FileH]) << 9
+}
+
+// NorthWest returns b with every bit moved one square diagonally toward A8.
+func (b Bitboard) NorthWest() Bitboard {
+	return (b &^ fileBitboards[FileA]) << 7
+}
+
+// SouthEast returns b with every bit moved one square diagonally toward H1.
+func (b Bitboard) SouthEast() Bitboard {
+	return (b &^ fileBitboards[FileH]) >> 7
+}
+
+// SouthWest returns b with every bit moved one square diagonally toward A1.
+func (b Bitboard) SouthWest() Bitboard {
+	return (b &^ fileBitboards[FileA]) >> 9
+}
+
 // Color represents a color, like [White].
 type Color bool
 
@@ -90,6 +160,53 @@ func (p PieceType) String() string {
 	}
 }
 
+// pieceValues holds the values of piece types in centipawns, indexed by
+// [PieceType].
+var pieceValues = [6]int{
+	Pawn:   100,
+	Knight: 320,
+	Bishop: 330,
+	Rook:   500,
+	Queen:  900,
+	King:   kingValue,
+}
+
+// kingValue is a sentinel value for kings, greater than all other material
+// combined, so that giving up the king never pays.
+const kingValue = 20000
+
+// Value returns the value of p in centipawns. Evaluation and static exchange
+// evaluation both use it, so they agree on what pieces are worth.
+//
+// Kings have a large sentinel value, since losing the king loses the game.
+// [Position.Material] leaves kings out.
+func (p PieceType) Value() int {
+	return pieceValues[p]
+}
+
+// PieceValues returns the values of all piece types in centipawns, indexed by
+// [PieceType], as returned by [PieceType.Value]. The result is a copy, so it
+// may be changed, for example to configure an evaluation, without affecting
+// SEE or [Position.Material].
+func PieceValues() [6]int {
+	return pieceValues
+}
+
+// material returns the value of p counted by [Position.Material], which is 0
+// for kings.
+func (p PieceType) material() int {
+	if p == King {
+		return 0
+	}
+	return pieceValues[p]
+}
+
+// letter returns the lowercase letter for p used by FEN and UCI, like 'n' for
+// [Knight].
+func (p PieceType) letter() byte {
+	return "pnbrqk"[p]
+}
+
 // Piece represents a piece.
 //
 // The zero value for Piece is a white pawn.
@@ -106,6 +223,22 @@ func NewPiece(c Color, pt PieceType) Piece {
 	}
 }
 
+// String implements [fmt.Stringer]. It returns the color and piece type, like
+// "White Knight".
+func (p Piece) String() string {
+	return p.Color.String() + " " + p.PieceType.String()
+}
+
+// Letter returns the FEN letter for p, like 'N' for a white knight or 'n' for
+// a black knight.
+func (p Piece) Letter() byte {
+	c := p.PieceType.letter()
+	if p.Color == White {
+		c -= 'a' - 'A'
+	}
+	return c
+}
+
 // File represents a file, like [FileA].
 type File uint8
 
@@ -131,7 +264,13 @@ func (f File) String() string {
 
 // Bitboard returns a bitboard with just squares on f set.
 func (f File) Bitboard() Bitboard {
-	return Bitboard(0x101010101010101 << f)
+	return fileBitboards[f]
+}
+
+// AdjacentFiles returns a bitboard with just squares on the files next to f
+// set.
+func AdjacentFiles(f File) Bitboard {
+	return adjacentFileBitboards[f]
 }
 
 // Rank represents a rank, like [Rank1].
@@ -159,7 +298,29 @@ func (r Rank) String() string {
 
 // Bitboard returns a bitboard with just squares on r set.
 func (r Rank) Bitboard() Bitboard {
-	return Bitboard(0xff << (r * 8))
+	return rankBitboards[r]
+}
+
+// Precomputed bitboards, indexed by [File] or [Rank].
+var (
+	fileBitboards         [8]Bitboard
+	rankBitboards         [8]Bitboard
+	adjacentFileBitboards [8]Bitboard
+)
+
+func init() {
+	for i := range 8 {
+		fileBitboards[i] = 0x101010101010101 << i
+		rankBitboards[i] = 0xff << (i * 8)
+	}
+	for f := FileA; f <= FileH; f++ {
+		if f > FileA {
+			adjacentFileBitboards[f] |= fileBitboards[f-1]
+		}
+		if f < FileH {
+			adjacentFileBitboards[f] |= fileBitboards[f+1]
+		}
+	}
 }
 
 // Square represents a square, like [A1].
@@ -250,6 +411,19 @@ func (s Square) String() string {
 	)
 }
 
+// appendSquare appends s in lowercase algebraic notation, like "e4".
+func appendSquare(b []byte, s Square) []byte {
+	return append(b, 'a'+byte(s.File()), '1'+byte(s.Rank()))
+}
+
+// parseSquare parses a square in lowercase algebraic notation, like "e4".
+func parseSquare(s string) (Square, error) {
+	if len(s) != 2 || s[0] < 'a' || s[0] > 'h' || s[1] < '1' || s[1] > '8' {
+		return 0, fmt.Errorf("invalid square %q", s)
+	}
+	return NewSquare(File(s[0]-'a'), Rank(s[1]-'1')), nil
+}
+
 // File returns the file that s is on.
 func (s Square) File() File {
 	return File(s % 8)
@@ -265,6 +439,13 @@ func (s Square) Bitboard() Bitboard {
 	return Bitboard(1 << s)
 }
 
+// Distance returns the number of king moves needed to travel between s and t.
+func (s Square) Distance(t Square) int {
+	df := int(s.File()) - int(t.File())
+	dr := int(s.Rank()) - int(t.Rank())
+	return max(df, -df, dr, -dr)
+}
+
 // Above returns the square above s, if any.
 func (s Square) Above() (Square, bool) {
 	if s.Rank() == Rank8 {
@@ -281,6 +462,67 @@ func (s Square) Below() (Square, bool) {
 	return s - 8, true
 }
 
+// Up is the same as [Square.Above].
+func (s Square) Up() (Square, bool) {
+	return s.Above()
+}
+
+// Down is the same as [Square.Below].
+func (s Square) Down() (Square, bool) {
+	return s.Below()
+}
+
+// Left returns the square to the left of s, on the next file towards the
+// a-file, if any.
+func (s Square) Left() (Square, bool) {
+	if s.File() == FileA {
+		return 0, false
+	}
+	return s - 1, true
+}
+
+// Right returns the square to the right of s, on the next file towards the
+// h-file, if any.
+func (s Square) Right() (Square, bool) {
+	if s.File() == FileH {
+		return 0, false
+	}
+	return s + 1, true
+}
+
+// UpLeft returns the square diagonally above and to the left of s, if any.
+func (s Square) UpLeft() (Square, bool) {
+	if t, ok := s.Up(); ok {
+		return t.Left()
+	}
+	return 0, false
+}
+
+// UpRight returns the square diagonally above and to the right of s, if any.
+func (s Square) UpRight() (Square, bool) {
+	if t, ok := s.Up(); ok {
+		return t.Right()
+	}
+	return 0, false
+}
+
+// DownLeft returns the square diagonally below and to the left of s, if any.
+func (s Square) DownLeft() (Square, bool) {
+	if t, ok := s.Down(); ok {
+		return t.Left()
+	}
+	return 0, false
+}
+
+// DownRight returns the square diagonally below and to the right of s, if
+// any.
+func (s Square) DownRight() (Square, bool) {
+	if t, ok := s.Down(); ok {
+		return t.Right()
+	}
+	return 0, false
+}
+
 // Castling represents a set of castling rights.
 //
 // The zero value indicates neither player has castling rights.
@@ -333,6 +575,33 @@ func (c *Castling) ClearColor(cl Color) {
 	c.Clear(lost)
 }
 
+// String implements [fmt.Stringer]. It lists the castling rights in c, like
+// "WhiteOO|BlackOOO", or returns "none" if there are none.
+func (c Castling) String() string {
+	if c == 0 {
+		return "none"
+	}
+
+	var names []string
+	for _, x := range []struct {
+		right Castling
+		name  string
+	}{
+		{WhiteOO, "WhiteOO"},
+		{WhiteOOO, "WhiteOOO"},
+		{BlackOO, "BlackOO"},
+		{BlackOOO, "BlackOOO"},
+	} {
+		if c&x.right != 0 {
+			names = append(names, x.name)
+		}
+	}
+	if rest := c &^ NewCastling(); rest != 0 {
+		names = append(names, fmt.Sprintf("Castling(%#x)", uint8(rest)))
+	}
+	return strings.Join(names, "|")
+}
+
 // EnPassant represents the right to capture en passant.
 //
 // The zero value indicates there is no right to capture en passant.
@@ -369,17 +638,44 @@ func (e *EnPassant) Clear() {
 }
 
 // Move represents a move.
+//
+// Castling moves are encoded as the king moving onto its own castling rook,
+// like E1 to H1, and are marked as castling. The encoding names both pieces
+// involved, so it works even in Chess960, where the king may castle by not
+// moving at all or by moving a single square. See [NewCastle].
 type Move struct {
 	// The moved piece, or king if castling, departs from this square.
 	from Square
 
-	// The moved piece, or king if castling, lands on this square.
+	// The moved piece lands on this square. If castling, this is the square
+	// of the castling rook instead.
 	to Square
 
 	// The moved piece promotes to this piece type.
 	//
 	// The zero value indicates no promotion occurs.
 	promotion PieceType
+
+	// Whether the move is a castling move.
+	castle bool
+}
+
+// NewMove returns a new [Move] without promotion.
+func NewMove(from, to Square) Move {
+	return Move{from: from, to: to}
+}
+
+// NewPromotion returns a new [Move] that promotes to pt.
+func NewPromotion(from, to Square, pt PieceType) Move {
+	return Move{from: from, to: to, promotion: pt}
+}
+
+// NewCastle returns a new castling [Move] for the king on king and the rook on
+// rook, which may be on any squares of the same back rank, as in Chess960.
+// Castling towards the h-file is kingside castling, and towards the a-file is
+// queenside castling.
+func NewCastle(king, rook Square) Move {
+	return Move{from: king, to: rook, castle: true}
 }
 
 // From returns the square the moved piece, or king if castling, departs from.
@@ -387,11 +683,44 @@ func (m Move) From() Square {
 	return m.from
 }
 
-// To returns the square the moved piece, or king if castling, lands on.
+// To returns the square the moved piece lands on. For castling moves, it
+// returns the square of the castling rook.
 func (m Move) To() Square {
 	return m.to
 }
 
+// IsCastleEncoded returns true if m is a castling move, encoded as the king
+// moving onto its own castling rook.
+func (m Move) IsCastleEncoded() bool {
+	return m.castle
+}
+
+// castlingSquares returns the squares that the king and rook of m, a castling
+// move, land on.
+func (m Move) castlingSquares() (king, rook Square) {
+	r := m.from.Rank()
+	if m.from < m.to {
+		return NewSquare(FileG, r), NewSquare(FileF, r)
+	}
+	return NewSquare(FileC, r), NewSquare(FileD, r)
+}
+
+// isStandardCastle returns true if m, a castling move, has the king and rook
+// on their standard starting squares, like E1 and H1.
+func (m Move) isStandardCastle() bool {
+	return m.from.File() == FileE && (m.to.File() == FileA || m.to.File() == FileH)
+}
+
+// target returns the square that the moved piece, or king if castling, lands
+// on.
+func (m Move) target() Square {
+	if m.castle {
+		king, _ := m.castlingSquares()
+		return king
+	}
+	return m.to
+}
+
 // IsPromotion returns true if the move is a promotion move.
 func (m Move) IsPromotion() bool {
 	return m.promotion != 0
@@ -401,3 +730,41 @@ func (m Move) IsPromotion() bool {
 func (m Move) PromotionTo() (PieceType, bool) {
 	return m.promotion, m.IsPromotion()
 }
+
+// String returns the move in UCI long algebraic notation, like "e2e4" or
+// "e7e8q". Castling moves with the king and rook on their standard starting
+// squares are written as the king's move, like "e1g1". Other castling moves,
+// as in Chess960, are written as the king moving onto its rook, like "c1b1",
+// since the king's move alone may be ambiguous or may not move the king at
+// all.
+func (m Move) String() string {
+	b, _ := m.AppendText(make([]byte, 0, 5))
+	return string(b)
+}
+
+// AppendText implements [encoding.TextAppender]. It appends the move in UCI
+// long algebraic notation.
+func (m Move) AppendText(b []byte) ([]byte, error) {
+	b = appendSquare(b, m.from)
+	if m.castle && !m.isStandardCastle() {
+		b = appendSquare(b, m.to)
+	} else {
+		b = appendSquare(b, m.target())
+	}
+	if pt, ok := m.PromotionTo(); ok {
+		b = append(b, pt.letter())
+	}
+	return b, nil
+}
+
+// AppendMoveList appends moves to b in UCI long algebraic notation, separated
+// by spaces, like "e2e4 e7e5 g1f3".
+func AppendMoveList(b []byte, moves []Move) []byte {
+	for i, m := range moves {
+		if i > 0 {
+			b = append(b, ' ')
+		}
+		b, _ = m.AppendText(b)
+	}
+	return b
+}
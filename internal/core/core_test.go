@@ -0,0 +1,360 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// testLine returns a sequence of n legal moves from the starting position.
+func testLine(n int) []Move {
+	var line []Move
+	p := NewPosition()
+	for i := range n {
+		moves := p.Moves()
+		m := moves[(i*7)%len(moves)]
+		line = append(line, m)
+		p.Move(m)
+	}
+	return line
+}
+
+// appendMoveListNaive is a straightforward but allocation-heavy equivalent of
+// [AppendMoveList].
+func appendMoveListNaive(b []byte, moves []Move) []byte {
+	var s []string
+	for _, m := range moves {
+		s = append(s, m.String())
+	}
+	return append(b, strings.Join(s, " ")...)
+}
+
+func TestAppendMoveList(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 30} {
+		line := testLine(n)
+		got := AppendMoveList([]byte("pv "), line)
+		want := appendMoveListNaive([]byte("pv "), line)
+		if string(got) != string(want) {
+			t.Errorf("AppendMoveList(%d moves): got %q, want %q", n, got, want)
+		}
+	}
+}
+
+func BenchmarkAppendMoveList(b *testing.B) {
+	line := testLine(30)
+	buf := make([]byte, 0, 256)
+	for b.Loop() {
+		buf = AppendMoveList(buf[:0], line)
+	}
+}
+
+func BenchmarkAppendMoveList_naive(b *testing.B) {
+	line := testLine(30)
+	buf := make([]byte, 0, 256)
+	for b.Loop() {
+		buf = appendMoveListNaive(buf[:0], line)
+	}
+}
+
+func TestBitboard_Next(t *testing.T) {
+	tests := []struct {
+		name string
+		b    Bitboard
+		want []Square
+	}{
+		{"empty", 0, nil},
+		{"corners", A1.Bitboard() | H1.Bitboard() | A8.Bitboard() | H8.Bitboard(), []Square{A1, H1, A8, H8}},
+		{"rank 2", Rank2.Bitboard(), []Square{A2, B2, C2, D2, E2, F2, G2, H2}},
+	}
+
+	for _, test := range tests {
+		var got []Square
+		b := test.b
+		for s, ok := b.Next(); ok; s, ok = b.Next() {
+			got = append(got, s)
+		}
+		if !slices.Equal(got, test.want) {
+			t.Errorf("%s: Next: got %v, want %v", test.name, got, test.want)
+		}
+		if b != 0 {
+			t.Errorf("%s: Next: got %#x left over, want 0", test.name, uint64(b))
+		}
+
+		got = slices.Collect(test.b.All)
+		if !slices.Equal(got, test.want) {
+			t.Errorf("%s: All: got %v, want %v", test.name, got, test.want)
+		}
+	}
+
+	// Every square, in order.
+	var got []Square
+	for s := range (^Bitboard(0)).All {
+		got = append(got, s)
+	}
+	if len(got) != 64 {
+		t.Fatalf("All: got %d squares, want 64", len(got))
+	}
+	for i, s := range got {
+		if s != Square(i) {
+			t.Errorf("All: got square %d %v, want %v", i, s, Square(i))
+		}
+	}
+}
+
+func TestBitboard_All_break(t *testing.T) {
+	var got []Square
+	for s := range Rank1.Bitboard().All {
+		if s == C1 {
+			break
+		}
+		got = append(got, s)
+	}
+	if want := []Square{A1, B1}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestBitboard_shifts(t *testing.T) {
+	full := ^Bitboard(0)
+	d4 := D4.Bitboard()
+
+	tests := []struct {
+		name string
+		got  Bitboard
+		want Bitboard
+	}{
+		{"A-file west", FileA.Bitboard().West(), 0},
+		{"H-file east", FileH.Bitboard().East(), 0},
+		{"A-file east", FileA.Bitboard().East(), FileB.Bitboard()},
+		{"H-file west", FileH.Bitboard().West(), FileG.Bitboard()},
+		{"full north", full.North(), full &^ Rank1.Bitboard()},
+		{"full south", full.South(), full &^ Rank8.Bitboard()},
+		{"rank 8 north", Rank8.Bitboard().North(), 0},
+		{"rank 1 south", Rank1.Bitboard().South(), 0},
+		{"A-file northwest", FileA.Bitboard().NorthWest(), 0},
+		{"A-file southwest", FileA.Bitboard().SouthWest(), 0},
+		{"H-file northeast", FileH.Bitboard().NorthEast(), 0},
+		{"H-file southeast", FileH.Bitboard().SouthEast(), 0},
+		{"d4 north", d4.North(), D5.Bitboard()},
+		{"d4 south", d4.South(), D3.Bitboard()},
+		{"d4 east", d4.East(), E4.Bitboard()},
+		{"d4 west", d4.West(), C4.Bitboard()},
+		{"d4 northeast", d4.NorthEast(), E5.Bitboard()},
+		{"d4 northwest", d4.NorthWest(), C5.Bitboard()},
+		{"d4 southeast", d4.SouthEast(), E3.Bitboard()},
+		{"d4 southwest", d4.SouthWest(), C3.Bitboard()},
+	}
+
+	for _, test := range tests {
+		if test.got != test.want {
+			t.Errorf("%s: got %#x, want %#x", test.name, uint64(test.got), uint64(test.want))
+		}
+	}
+}
+
+func TestSquare_Distance(t *testing.T) {
+	tests := []struct {
+		a, b Square
+		want int
+	}{
+		{A1, A1, 0},
+		{A1, B2, 1},
+		{A1, H8, 7},
+		{E4, C5, 2},
+		{H1, A2, 7},
+	}
+	for _, test := range tests {
+		if got := test.a.Distance(test.b); got != test.want {
+			t.Errorf("%v.Distance(%v): got %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestFileRank_Bitboard(t *testing.T) {
+	for i := range 8 {
+		f, r := File(i), Rank(i)
+		var wantFile, wantRank Bitboard
+		for s := A1; s <= H8; s++ {
+			if s.File() == f {
+				wantFile.Set(s)
+			}
+			if s.Rank() == r {
+				wantRank.Set(s)
+			}
+		}
+		if got := f.Bitboard(); got != wantFile {
+			t.Errorf("%v.Bitboard(): got %#x, want %#x", f, uint64(got), uint64(wantFile))
+		}
+		if got := r.Bitboard(); got != wantRank {
+			t.Errorf("%v.Bitboard(): got %#x, want %#x", r, uint64(got), uint64(wantRank))
+		}
+	}
+}
+
+func TestAdjacentFiles(t *testing.T) {
+	tests := []struct {
+		f    File
+		want Bitboard
+	}{
+		{FileA, FileB.Bitboard()},
+		{FileB, FileA.Bitboard() | FileC.Bitboard()},
+		{FileE, FileD.Bitboard() | FileF.Bitboard()},
+		{FileH, FileG.Bitboard()},
+	}
+
+	for _, test := range tests {
+		if got := AdjacentFiles(test.f); got != test.want {
+			t.Errorf("AdjacentFiles(%v): got %#x, want %#x", test.f, uint64(got), uint64(test.want))
+		}
+	}
+}
+
+func TestSquare_neighbors(t *testing.T) {
+	tests := []struct {
+		name   string
+		f      func(Square) (Square, bool)
+		df, dr int
+	}{
+		{"Up", Square.Up, 0, 1},
+		{"Down", Square.Down, 0, -1},
+		{"Left", Square.Left, -1, 0},
+		{"Right", Square.Right, 1, 0},
+		{"Above", Square.Above, 0, 1},
+		{"Below", Square.Below, 0, -1},
+		{"UpLeft", Square.UpLeft, -1, 1},
+		{"UpRight", Square.UpRight, 1, 1},
+		{"DownLeft", Square.DownLeft, -1, -1},
+		{"DownRight", Square.DownRight, 1, -1},
+	}
+
+	for _, test := range tests {
+		for s := A1; s <= H8; s++ {
+			f, r := int(s.File())+test.df, int(s.Rank())+test.dr
+			onBoard := f >= 0 && f <= 7 && r >= 0 && r <= 7
+
+			got, ok := test.f(s)
+			if ok != onBoard {
+				t.Errorf("%v.%s(): got ok %v, want %v", s, test.name, ok, onBoard)
+				continue
+			}
+			if want := NewSquare(File(f), Rank(r)); ok && got != want {
+				t.Errorf("%v.%s(): got %v, want %v", s, test.name, got, want)
+			}
+		}
+	}
+}
+
+func TestMove_IsCastleEncoded(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		s    string
+		want bool
+	}{
+		{"kingside", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "e1g1", true},
+		{"queenside", "r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1", "e8c8", true},
+		{"king takes own rook", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "e1h1", true},
+		{"king takes own rook without the right", "r3k2r/8/8/8/8/8/8/R3K2R w Qkq - 0 1", "e1h1", false},
+		{"960 king next to rook", "4k3/8/8/8/8/8/8/5KR1 w K - 0 1", "f1g1", true},
+		{"960 king stays put", "4k3/8/8/8/8/8/8/1RK5 w Q - 0 1", "c1b1", true},
+		{"960 black king takes own rook", "1rk5/8/8/8/8/8/8/4K3 b q - 0 1", "c8b8", true},
+		{"960 king on e1 next to rook", "4k3/8/8/8/8/8/8/4KR2 w K - 0 1", "e1f1", true},
+		{"960 king on e1 takes rook on g1", "4k3/8/8/8/8/8/8/4K1R1 w K - 0 1", "e1g1", true},
+		{"960 king next to rook without the right", "4k3/8/8/8/8/8/8/5KR1 w Q - 0 1", "f1g1", false},
+		{"king move", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "e1f1", false},
+		{"king takes enemy rook", "4k3/8/8/8/8/8/8/4Kr2 w - - 0 1", "e1f1", false},
+		{"rook move", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "h1g1", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := ParseFENLenient(test.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+			m, err := p.ParseMove(test.s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := m.IsCastleEncoded(); got != test.want {
+				t.Errorf("ParseMove(%q).IsCastleEncoded(): got %v, want %v", test.s, got, test.want)
+			}
+			// Castles survive a round trip through their UCI notation.
+			if again, err := p.ParseMove(m.String()); test.want && (err != nil || again != m) {
+				t.Errorf("ParseMove(%q): got %v, %v, want %#v", m.String(), again, err, m)
+			}
+		})
+	}
+}
+
+func TestPiece_String(t *testing.T) {
+	tests := []struct {
+		p          Piece
+		want       string
+		wantLetter byte
+	}{
+		{NewPiece(Black, Queen), "Black Queen", 'q'},
+		{NewPiece(White, Pawn), "White Pawn", 'P'},
+		{NewPiece(White, Knight), "White Knight", 'N'},
+	}
+
+	for _, test := range tests {
+		if got := test.p.String(); got != test.want {
+			t.Errorf("%#v.String(): got %q, want %q", test.p, got, test.want)
+		}
+		if got := test.p.Letter(); got != test.wantLetter {
+			t.Errorf("%#v.Letter(): got %q, want %q", test.p, got, test.wantLetter)
+		}
+	}
+}
+
+func TestCastling_String(t *testing.T) {
+	tests := []struct {
+		c    Castling
+		want string
+	}{
+		{0, "none"},
+		{NewCastling(), "WhiteOO|WhiteOOO|BlackOO|BlackOOO"},
+		{WhiteOO | BlackOOO, "WhiteOO|BlackOOO"},
+		{BlackOO, "BlackOO"},
+		{WhiteOOO | 0x80, "WhiteOOO|Castling(0x80)"},
+	}
+
+	for _, test := range tests {
+		if got := test.c.String(); got != test.want {
+			t.Errorf("Castling(%#x).String(): got %q, want %q", uint8(test.c), got, test.want)
+		}
+	}
+}
+
+func TestMove_String(t *testing.T) {
+	tests := []struct {
+		m    Move
+		want string
+	}{
+		{NewMove(E2, E4), "e2e4"},
+		{NewMove(E4, D5), "e4d5"}, // A capture looks like any other move.
+		{NewMove(H8, A1), "h8a1"},
+		{NewPromotion(E7, E8, Queen), "e7e8q"},
+		{NewPromotion(E7, E8, Rook), "e7e8r"},
+		{NewPromotion(E7, E8, Bishop), "e7e8b"},
+		{NewPromotion(B2, A1, Knight), "b2a1n"},
+		{NewCastle(E1, H1), "e1g1"},
+		{NewCastle(E8, A8), "e8c8"},
+		{NewCastle(F1, G1), "f1g1"}, // Chess960 castles move the king onto its rook.
+		{NewCastle(C1, B1), "c1b1"},
+		{NewCastle(E1, G1), "e1g1"},
+		{Move{}, "a1a1"},
+	}
+
+	for _, test := range tests {
+		if got := test.m.String(); got != test.want {
+			t.Errorf("%#v.String(): got %q, want %q", test.m, got, test.want)
+		}
+		// Formatting verbs use the String method too.
+		if got := fmt.Sprintf("%v", test.m); got != test.want {
+			t.Errorf("Sprintf(%%v, %#v): got %q, want %q", test.m, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StartFEN is the starting position in Forsyth-Edwards Notation.
+const StartFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+// ParseFEN parses a position in Forsyth-Edwards Notation.
+//
+// The halfmove clock and fullmove number fields may be omitted, in which case
+// they default to 0 and 1 respectively.
+//
+// ParseFEN is strict: it returns an error if the en passant square could not
+// have resulted from a double pawn push on the previous move.
+func ParseFEN(s string) (Position, error) {
+	return parseFEN(s, true)
+}
+
+// ParseFENLenient is like [ParseFEN], but it clears an en passant square that
+// could not have resulted from a double pawn push on the previous move instead
+// of returning an error.
+func ParseFENLenient(s string) (Position, error) {
+	return parseFEN(s, false)
+}
+
+// parseFEN parses a position in Forsyth-Edwards Notation. If strict is true,
+// it returns an error if the position is inconsistent.
+func parseFEN(s string, strict bool) (Position, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 4 && len(fields) != 6 {
+		return Position{}, fmt.Errorf("invalid FEN %q: want 4 or 6 fields, got %d", s, len(fields))
+	}
+
+	var p Position
+
+	if err := parseFENBoard(&p.Board, fields[0]); err != nil {
+		return Position{}, fmt.Errorf("invalid FEN %q: %w", s, err)
+	}
+
+	switch fields[1] {
+	case "w":
+		p.Turn = White
+	case "b":
+		p.Turn = Black
+	default:
+		return Position{}, fmt.Errorf("invalid FEN %q: invalid turn %q", s, fields[1])
+	}
+
+	if fields[2] != "-" {
+		for _, r := range fields[2] {
+			var c Castling
+			switch r {
+			case 'K':
+				c = WhiteOO
+			case 'Q':
+				c = WhiteOOO
+			case 'k':
+				c = BlackOO
+			case 'q':
+				c = BlackOOO
+			default:
+				return Position{}, fmt.Errorf("invalid FEN %q: invalid castling rights %q", s, fields[2])
+			}
+			p.Castling.Set(c)
+		}
+	}
+
+	if fields[3] != "-" {
+		sq, err := parseSquare(fields[3])
+		if err != nil {
+			return Position{}, fmt.Errorf("invalid FEN %q: %w", s, err)
+		}
+		if r := sq.Rank(); r != Rank3 && r != Rank6 {
+			return Position{}, fmt.Errorf("invalid FEN %q: invalid en passant square %q", s, fields[3])
+		}
+		p.EnPassant.Set(sq)
+	}
+
+	if !p.validEnPassant() {
+		if strict {
+			return Position{}, fmt.Errorf("invalid FEN %q: en passant square %q does not follow a double pawn push", s, fields[3])
+		}
+		p.EnPassant.Clear()
+	}
+
+	if strict {
+		if err := p.Validate(); err != nil {
+			return Position{}, fmt.Errorf("invalid FEN %q: %w", s, err)
+		}
+	}
+
+	p.hash = p.zobrist()
+
+	if len(fields) == 4 {
+		if p.Turn == Black {
+			p.Plies = 1
+		}
+		return p, nil
+	}
+
+	halfmove, err := strconv.ParseUint(fields[4], 10, 8)
+	if err != nil {
+		return Position{}, fmt.Errorf("invalid FEN %q: invalid halfmove clock %q", s, fields[4])
+	}
+	p.FiftyMoveRule = uint8(halfmove)
+
+	fullmove, err := strconv.ParseUint(fields[5], 10, 16)
+	if err != nil || fullmove == 0 {
+		return Position{}, fmt.Errorf("invalid FEN %q: invalid fullmove number %q", s, fields[5])
+	}
+	p.Plies = uint16(2 * (fullmove - 1))
+	if p.Turn == Black {
+		p.Plies++
+	}
+
+	return p, nil
+}
+
+// PositionsEqualFEN returns true if p is the position described by fen. It
+// returns false if fen is invalid.
+func PositionsEqualFEN(p Position, fen string) bool {
+	q, err := ParseFEN(fen)
+	return err == nil && p == q
+}
+
+// FEN returns p in Forsyth-Edwards Notation.
+func (p *Position) FEN() string {
+	var b []byte
+
+	for r := Rank8; r <= Rank8; r-- {
+		empty := 0
+		for f := FileA; f <= FileH; f++ {
+			piece, ok := p.Board.Piece(NewSquare(f, r))
+			if !ok {
+				empty++
+				continue
+			}
+			if empty > 0 {
+				b = append(b, byte('0'+empty))
+				empty = 0
+			}
+			b = append(b, piece.Letter())
+		}
+		if empty > 0 {
+			b = append(b, byte('0'+empty))
+		}
+		if r > Rank1 {
+			b = append(b, '/')
+		}
+	}
+
+	if p.Turn == White {
+		b = append(b, " w "...)
+	} else {
+		b = append(b, " b "...)
+	}
+
+	if p.Castling == 0 {
+		b = append(b, '-')
+	}
+	for i, c := range []Castling{WhiteOO, WhiteOOO, BlackOO, BlackOOO} {
+		if p.Castling.GetAll(c) {
+			b = append(b, "KQkq"[i])
+		}
+	}
+
+	if s, ok := p.EnPassant.Square(); ok {
+		b = append(b, ' ')
+		b = appendSquare(b, s)
+	} else {
+		b = append(b, " -"...)
+	}
+
+	b = fmt.Appendf(b, " %d %d", p.FiftyMoveRule, p.Plies/2+1)
+
+	return string(b)
+}
+
+// parseFENBoard parses the piece placement field of a FEN into b.
+func parseFENBoard(b *Board, s string) error {
+	ranks := strings.Split(s, "/")
+	if len(ranks) != 8 {
+		return fmt.Errorf("invalid piece placement %q: want 8 ranks, got %d", s, len(ranks))
+	}
+
+	for i, rank := range ranks {
+		r := Rank8 - Rank(i)
+		f := FileA
+		for _, c := range rank {
+			if f > FileH {
+				return fmt.Errorf("invalid piece placement %q: too many squares on %v", s, r)
+			}
+			if c >= '1' && c <= '8' {
+				f += File(c - '0')
+				continue
+			}
+			p, ok := pieceFromLetter(byte(c))
+			if !ok {
+				return fmt.Errorf("invalid piece placement %q: invalid piece %q", s, c)
+			}
+			b.Set(p, NewSquare(f, r))
+			f++
+		}
+		if f != FileH+1 {
+			return fmt.Errorf("invalid piece placement %q: wrong number of squares on %v", s, r)
+		}
+	}
+
+	return nil
+}
+
+// pieceFromLetter returns the piece for a FEN letter, like 'N' for a white
+// knight or 'n' for a black knight.
+func pieceFromLetter(c byte) (Piece, bool) {
+	color := White
+	if c >= 'a' && c <= 'z' {
+		color = Black
+		c -= 'a' - 'A'
+	}
+	pt, ok := pieceTypeFromLetter(c)
+	if !ok {
+		return Piece{}, false
+	}
+	return NewPiece(color, pt), true
+}
+
+// validEnPassant returns true if the right to capture en passant, if any,
+// could have resulted from a double pawn push on the previous move. That is,
+// the pushed pawn is in place, and the square it skipped and the square it
+// left are empty.
+func (p *Position) validEnPassant() bool {
+	target, ok := p.EnPassant.Square()
+	if !ok {
+		return true
+	}
+
+	// Find the square the pawn left and the square it landed on.
+	var from, to Square
+	if p.Turn == White {
+		if target.Rank() != Rank6 {
+			return false
+		}
+		from, _ = target.Above()
+		to, _ = target.Below()
+	} else {
+		if target.Rank() != Rank3 {
+			return false
+		}
+		from, _ = target.Below()
+		to, _ = target.Above()
+	}
+
+	pawn, ok := p.Board.Piece(to)
+	return ok && pawn == NewPiece(p.Turn.Other(), Pawn) &&
+		!p.Board.IsOccupied(target) &&
+		!p.Board.IsOccupied(from)
+}
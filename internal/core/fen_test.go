@@ -0,0 +1,160 @@
+package core
+
+import "testing"
+
+func TestParseFEN(t *testing.T) {
+	got, err := ParseFEN(StartFEN)
+	if err != nil {
+		t.Fatalf("ParseFEN(%q): %v", StartFEN, err)
+	}
+	if want := NewPosition(); got != want {
+		t.Errorf("ParseFEN(%q): got %#v, want %#v", StartFEN, got, want)
+	}
+}
+
+func TestParseFEN_error(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+	}{
+		{"empty", ""},
+		{"missing fields", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w"},
+		{"too few ranks", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP w KQkq - 0 1"},
+		{"too many squares", "rnbqkbnr/ppppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"},
+		{"invalid piece", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNX w KQkq - 0 1"},
+		{"invalid turn", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR x KQkq - 0 1"},
+		{"invalid castling", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkx - 0 1"},
+		{"invalid fullmove number", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 0"},
+		{"side not to move in check", "4k3/8/8/8/8/8/8/4RK2 w - - 0 1"},
+		{"side not to move in check by pawn", "4k3/8/8/8/8/8/3p4/4K3 b - - 0 1"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := ParseFEN(test.fen); err == nil {
+				t.Errorf("ParseFEN(%q): got nil error", test.fen)
+			}
+		})
+	}
+}
+
+func TestParseFEN_enPassant(t *testing.T) {
+	tests := []struct {
+		name  string
+		fen   string
+		valid bool
+	}{
+		{
+			name:  "after e2e4",
+			fen:   "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1",
+			valid: true,
+		},
+		{
+			name:  "after d7d5",
+			fen:   "rnbqkbnr/ppp1pppp/8/3p4/4P3/8/PPPP1PPP/RNBQKBNR w KQkq d6 0 2",
+			valid: true,
+		},
+		{
+			name:  "no pushed pawn",
+			fen:   "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR b KQkq e3 0 1",
+			valid: false,
+		},
+		{
+			name:  "wrong player's pawn",
+			fen:   "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR w KQkq e3 0 1",
+			valid: false,
+		},
+		{
+			name:  "skipped square occupied",
+			fen:   "rnbqkbnr/pppppppp/8/8/4P3/4N3/PPPP1PPP/RNBQKB1R b KQkq e3 0 1",
+			valid: false,
+		},
+		{
+			name:  "departure square occupied",
+			fen:   "rnbqkbnr/pppppppp/8/8/4P3/8/PPPPQPPP/RNB1KBNR b KQkq e3 0 1",
+			valid: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseFEN(test.fen)
+			if gotValid := err == nil; gotValid != test.valid {
+				t.Errorf("ParseFEN(%q): got error %v, want valid %v", test.fen, err, test.valid)
+			}
+
+			p, err := ParseFENLenient(test.fen)
+			if err != nil {
+				t.Fatalf("ParseFENLenient(%q): %v", test.fen, err)
+			}
+			if got := p.EnPassant.Exists(); got != test.valid {
+				t.Errorf("ParseFENLenient(%q): got en passant %v, want %v", test.fen, got, test.valid)
+			}
+		})
+	}
+}
+
+func TestPosition_Validate(t *testing.T) {
+	const fen = "4k3/8/8/8/8/8/8/4RK2 w - - 0 1"
+
+	// The lenient parser leaves validation to the caller.
+	p, err := ParseFENLenient(fen)
+	if err != nil {
+		t.Fatalf("ParseFENLenient(%q): %v", fen, err)
+	}
+	err = p.Validate()
+	if err == nil {
+		t.Fatalf("Validate(%q): got nil error", fen)
+	}
+	if got, want := err.Error(), "Black is in check, but it is White's turn"; got != want {
+		t.Errorf("Validate(%q): got error %q, want %q", fen, got, want)
+	}
+
+	// The side to move may be in check.
+	p.Turn = Black
+	if err := p.Validate(); err != nil {
+		t.Errorf("Validate with Black to move: %v", err)
+	}
+}
+
+func TestPosition_FEN(t *testing.T) {
+	fens := []string{
+		StartFEN,
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+		"rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3",
+		"r3k3/8/8/8/8/8/8/4K2R b Kq - 12 40",
+	}
+
+	for _, fen := range fens {
+		p, err := ParseFEN(fen)
+		if err != nil {
+			t.Fatalf("ParseFEN(%q): %v", fen, err)
+		}
+		if got := p.FEN(); got != fen {
+			t.Errorf("FEN(%q): got %q", fen, got)
+		}
+	}
+}
+
+func TestPositionsEqualFEN(t *testing.T) {
+	p := NewPosition()
+
+	tests := []struct {
+		fen  string
+		want bool
+	}{
+		{StartFEN, true},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR b KQkq - 0 1", false},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w Kkq - 0 1", false},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 1 1", false},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 2", false},
+		{"not a fen", false},
+	}
+
+	for _, test := range tests {
+		if got := PositionsEqualFEN(p, test.fen); got != test.want {
+			t.Errorf("PositionsEqualFEN(%q): got %v, want %v", test.fen, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package core
+
+import "fmt"
+
+// Game represents a game in progress, including the positions that led to the
+// current position.
+type Game struct {
+	// Earlier positions, oldest first.
+	history []Position
+
+	// The current position.
+	position Position
+}
+
+// NewGame returns a new [Game] starting from p.
+func NewGame(p Position) *Game {
+	return &Game{position: p}
+}
+
+// Position returns the current position.
+func (g *Game) Position() Position {
+	return g.position
+}
+
+// Move makes a move.
+//
+// It does not check for invalid moves.
+func (g *Game) Move(m Move) {
+	g.history = append(g.history, g.position)
+	g.position.Move(m)
+}
+
+// HistoryKeys returns the hashes of the earlier positions, oldest first, as
+// given by [Position.Hash].
+func (g *Game) HistoryKeys() []Key {
+	keys := make([]Key, len(g.history))
+	for i := range g.history {
+		keys[i] = g.history[i].Hash()
+	}
+	return keys
+}
+
+// FENAt returns the position ply plies after the start of the game in
+// Forsyth-Edwards Notation. Ply 0 is the position the game started from, and
+// the current position is at the ply equal to the number of moves made.
+func (g *Game) FENAt(ply int) (string, error) {
+	switch {
+	case ply < 0 || ply > len(g.history):
+		return "", fmt.Errorf("ply %d out of range [0, %d]", ply, len(g.history))
+	case ply == len(g.history):
+		return g.position.FEN(), nil
+	default:
+		return g.history[ply].FEN(), nil
+	}
+}
+
+// Repetitions returns the number of times the current position has occurred,
+// including the current occurrence.
+//
+// Positions are considered identical if they have the same pieces on the same
+// squares, the same player to move, the same castling rights, and the same
+// right to capture en passant. The right to capture en passant only counts if
+// a pawn is in place to make the capture.
+//
+// Only positions since the most recent capture or pawn advance are scanned,
+// since no earlier position can recur.
+func (g *Game) Repetitions() int {
+	n := 1
+
+	// Positions with the other player to move can't be identical, so step
+	// back two plies at a time.
+	window := min(int(g.position.FiftyMoveRule), len(g.history))
+	for i := 2; i <= window; i += 2 {
+		if g.history[len(g.history)-i].samePosition(&g.position) {
+			n++
+		}
+	}
+
+	return n
+}
+
+// IsThreefoldRepetition returns true if the current position has occurred at
+// least three times.
+func (g *Game) IsThreefoldRepetition() bool {
+	return g.Repetitions() >= 3
+}
+
+// Thresholds of the halfmove clock, [Position.FiftyMoveRule]. The clock counts
+// plies, so a "move" in the name of each rule is two plies: one by each
+// player.
+const (
+	// A draw may be claimed under the 50-move rule.
+	fiftyMovePlies = 100
+
+	// The game is drawn automatically under the 75-move rule.
+	seventyFiveMovePlies = 150
+)
+
+// CanClaimFiftyMoveRule returns true if a draw may be claimed under the
+// 50-move rule: each player has made 50 moves without a capture or pawn
+// advance.
+//
+// Like threefold repetition, the draw must be claimed, so it doesn't end the
+// game. After 75 moves, the game ends automatically; see [Game.IsOver].
+func (g *Game) CanClaimFiftyMoveRule() bool {
+	return g.position.FiftyMoveRule >= fiftyMovePlies
+}
+
+// IsOver returns true if the game has ended automatically, by checkmate,
+// stalemate, insufficient material, the 75-move rule, or fivefold repetition.
+//
+// Draws that must be claimed, like threefold repetition, don't end the game.
+func (g *Game) IsOver() bool {
+	_, ok := g.Outcome()
+	return ok
+}
+
+// Outcome returns how the game ended, if it has ended automatically. See
+// [Game.IsOver].
+func (g *Game) Outcome() (Outcome, bool) {
+	if o, ok := g.position.IsGameOver(); ok {
+		return o, true
+	}
+	if g.Repetitions() >= 5 {
+		return Outcome{Termination: FivefoldRepetition}, true
+	}
+	return Outcome{}, false
+}
+
+// Termination describes how a game ended.
+type Termination uint8
+
+// [Termination] constants.
+const (
+	// The player to move is checkmated.
+	Checkmate Termination = iota + 1
+
+	// The player to move has no legal moves but is not in check.
+	Stalemate
+
+	// Neither player can checkmate. See [Position.IsInsufficientMaterial].
+	InsufficientMaterial
+
+	// 75 moves by each player have passed without a capture or pawn advance.
+	SeventyFiveMoveRule
+
+	// The same position has occurred five times.
+	FivefoldRepetition
+)
+
+// String implements [fmt.Stringer].
+func (t Termination) String() string {
+	switch t {
+	case Checkmate:
+		return "checkmate"
+	case Stalemate:
+		return "stalemate"
+	case InsufficientMaterial:
+		return "insufficient material"
+	case SeventyFiveMoveRule:
+		return "75-move rule"
+	case FivefoldRepetition:
+		return "fivefold repetition"
+	default:
+		return fmt.Sprintf("Termination(%d)", t)
+	}
+}
+
+// An Outcome describes how a game ended.
+type Outcome struct {
+	// How the game ended.
+	Termination Termination
+
+	// The player who won. It is only meaningful if Termination is
+	// [Checkmate], since every other termination is a draw.
+	Winner Color
+}
+
+// IsDraw returns true if the game ended in a draw.
+func (o Outcome) IsDraw() bool {
+	return o.Termination != Checkmate
+}
+
+// IsGameOver returns how the game ended, if it has ended automatically in p:
+// by checkmate, stalemate, insufficient material, or the 75-move rule.
+//
+// Fivefold repetition depends on earlier positions, so it is left to
+// [Game.Outcome]. Draws that must be claimed don't end the game.
+func (p *Position) IsGameOver() (Outcome, bool) {
+	noMoves := len(p.Moves()) == 0
+	switch {
+	case noMoves && p.InCheck():
+		return Outcome{Termination: Checkmate, Winner: p.Turn.Other()}, true
+	case noMoves:
+		return Outcome{Termination: Stalemate}, true
+	case p.IsInsufficientMaterial():
+		return Outcome{Termination: InsufficientMaterial}, true
+	case p.IsSeventyFiveMoveRule():
+		return Outcome{Termination: SeventyFiveMoveRule}, true
+	default:
+		return Outcome{}, false
+	}
+}
+
+// IsSeventyFiveMoveRule returns true if the halfmove clock has reached the
+// 75-move rule: each player has made 75 moves without a capture or pawn
+// advance. The game is drawn unless the last move was checkmate, which
+// [Position.IsGameOver] checks first.
+func (p *Position) IsSeventyFiveMoveRule() bool {
+	return p.FiftyMoveRule >= seventyFiveMovePlies
+}
+
+// darkSquares has the dark squares, like A1, set.
+const darkSquares Bitboard = 0xaa55aa55aa55aa55
+
+// IsInsufficientMaterial returns true if neither player has enough material
+// to checkmate, no matter how badly the other plays. That's the case when
+// only kings and at most one knight or bishop remain, or when the only other
+// pieces are bishops on squares of the same color.
+func (p *Position) IsInsufficientMaterial() bool {
+	b := &p.Board
+	if b.pieces[Pawn]|b.pieces[Rook]|b.pieces[Queen] != 0 {
+		return false
+	}
+
+	minors := b.pieces[Knight] | b.pieces[Bishop]
+	if minors.Count() <= 1 {
+		return true
+	}
+
+	bishops := b.pieces[Bishop]
+	return b.pieces[Knight] == 0 && (bishops&darkSquares == 0 || bishops&^darkSquares == 0)
+}
+
+// samePosition returns true if p and q are identical for the purpose of
+// detecting repetitions. Move counters are ignored.
+func (p *Position) samePosition(q *Position) bool {
+	return p.Board == q.Board &&
+		p.Turn == q.Turn &&
+		p.Castling == q.Castling &&
+		p.capturableEnPassant() == q.capturableEnPassant()
+}
+
+// capturableEnPassant returns the right to capture en passant if a pawn of the
+// player whose turn it is attacks the en passant square. Otherwise, it returns
+// the zero [EnPassant].
+func (p *Position) capturableEnPassant() EnPassant {
+	s, ok := p.EnPassant.Square()
+	if !ok {
+		return 0
+	}
+	// A pawn of the player to move attacks s if a pawn of the other player on
+	// s would attack it.
+	pawns := p.Board.Pieces(NewPiece(p.Turn, Pawn))
+	if pawnAttacks[colorIndex(p.Turn.Other())][s]&pawns == 0 {
+		return 0
+	}
+	return p.EnPassant
+}
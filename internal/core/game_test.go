@@ -0,0 +1,309 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// playGame plays moves, given in UCI long algebraic notation separated by
+// spaces, from the starting position.
+func playGame(t *testing.T, moves string) *Game {
+	t.Helper()
+	g := NewGame(NewPosition())
+	for _, s := range strings.Fields(moves) {
+		p := g.Position()
+		m, err := p.ParseMove(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.Move(m)
+	}
+	return g
+}
+
+func TestGame_Repetitions(t *testing.T) {
+	tests := []struct {
+		name  string
+		moves string
+		want  int
+	}{
+		{
+			name:  "no moves",
+			moves: "",
+			want:  1,
+		},
+		{
+			name:  "knights out and back",
+			moves: "g1f3 g8f6 f3g1 f6g8",
+			want:  2,
+		},
+		{
+			name:  "knights out and back twice",
+			moves: "g1f3 g8f6 f3g1 f6g8 g1f3 g8f6 f3g1 f6g8",
+			want:  3,
+		},
+		{
+			name:  "repeats separated by pawn moves",
+			moves: "g1f3 g8f6 f3g1 f6g8 e2e4 e7e5 g1f3 g8f6 f3g1 f6g8",
+			want:  2,
+		},
+		{
+			name:  "en passant possible at first occurrence",
+			moves: "e2e4 g8f6 e4e5 d7d5 g1f3 f6g8 f3g1 g8f6 g1f3 f6g8 f3g1 g8f6",
+			want:  2,
+		},
+		{
+			name:  "same squares, other player to move",
+			moves: "g1f3 g8f6 f3g1 f6g8 b1c3",
+			want:  1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := playGame(t, test.moves)
+			if got := g.Repetitions(); got != test.want {
+				t.Errorf("got %d repetitions, want %d", got, test.want)
+			}
+			if got, want := g.IsThreefoldRepetition(), test.want >= 3; got != want {
+				t.Errorf("IsThreefoldRepetition: got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestGame_Repetitions_fromFEN(t *testing.T) {
+	// The halfmove clock is larger than the game's history.
+	p, err := ParseFEN("4k3/8/8/8/8/8/8/4K3 w - - 90 60")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewGame(p)
+	for _, m := range []Move{NewMove(E1, E2), NewMove(E8, E7), NewMove(E2, E1), NewMove(E7, E8)} {
+		g.Move(m)
+	}
+	if got := g.Repetitions(); got != 2 {
+		t.Errorf("got %d repetitions, want 2", got)
+	}
+}
+
+func TestGame_HistoryKeys(t *testing.T) {
+	g := playGame(t, "g1f3 g8f6 f3g1")
+	keys := g.HistoryKeys()
+	if len(keys) != 3 {
+		t.Fatalf("got %d keys, want 3", len(keys))
+	}
+	start := NewPosition()
+	if keys[0] != start.Hash() {
+		t.Errorf("got first key %#x, want %#x", keys[0], start.Hash())
+	}
+
+	// The starting position comes around again.
+	g = playGame(t, "g1f3 g8f6 f3g1 f6g8")
+	p := g.Position()
+	if keys := g.HistoryKeys(); keys[0] != p.Hash() {
+		t.Errorf("got first key %#x, want current key %#x", keys[0], p.Hash())
+	}
+}
+
+func TestGame_FENAt(t *testing.T) {
+	g := playGame(t, "e2e4 c7c5 g1f3")
+
+	tests := []struct {
+		ply  int
+		want string
+	}{
+		{0, StartFEN},
+		{1, "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1"},
+		{2, "rnbqkbnr/pp1ppppp/8/2p5/4P3/8/PPPP1PPP/RNBQKBNR w KQkq c6 0 2"},
+		{3, "rnbqkbnr/pp1ppppp/8/2p5/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq - 1 2"},
+	}
+	for _, test := range tests {
+		got, err := g.FENAt(test.ply)
+		if err != nil {
+			t.Errorf("FENAt(%d): %v", test.ply, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("FENAt(%d): got %q, want %q", test.ply, got, test.want)
+		}
+	}
+
+	p := g.Position()
+	if got, _ := g.FENAt(3); got != p.FEN() {
+		t.Errorf("FENAt(3): got %q, want current position %q", got, p.FEN())
+	}
+
+	for _, ply := range []int{-1, 4} {
+		if _, err := g.FENAt(ply); err == nil {
+			t.Errorf("FENAt(%d): got nil error", ply)
+		}
+	}
+}
+
+func TestPosition_IsInsufficientMaterial(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		want bool
+	}{
+		{"kings only", "4k3/8/8/8/8/8/8/4K3 w - - 0 1", true},
+		{"knight", "4k3/8/8/8/8/8/8/1N2K3 w - - 0 1", true},
+		{"bishop", "4k3/8/8/8/8/8/8/2B1K3 w - - 0 1", true},
+		{"bishops on dark squares", "4kb2/8/8/8/8/8/8/2B1K3 w - - 0 1", true},
+		{"bishops on both colors", "2b1k3/8/8/8/8/8/8/2B1K3 w - - 0 1", false},
+		{"two knights", "4k3/8/8/8/8/8/8/1N2K1N1 w - - 0 1", false},
+		{"knight and bishop", "4k3/8/8/8/8/8/8/1NB1K3 w - - 0 1", false},
+		{"pawn", "4k3/8/8/8/8/8/4P3/4K3 w - - 0 1", false},
+		{"rook", "4k3/8/8/8/8/8/8/R3K3 w - - 0 1", false},
+		{"start", StartFEN, false},
+	}
+
+	for _, test := range tests {
+		p, err := ParseFEN(test.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := p.IsInsufficientMaterial(); got != test.want {
+			t.Errorf("%s: IsInsufficientMaterial(): got %t, want %t", test.name, got, test.want)
+		}
+	}
+}
+
+func TestGame_IsOver(t *testing.T) {
+	tests := []struct {
+		name  string
+		fen   string
+		moves string
+		want  bool
+	}{
+		{"start", StartFEN, "", false},
+		{"checkmate", StartFEN, "f2f3 e7e5 g2g4 d8h4", true},
+		{"stalemate", "7k/5Q2/6K1/8/8/8/8/8 b - - 0 1", "", true},
+		{"capture leaves kings only", "4k3/8/8/8/8/8/3q4/4K3 w - - 0 1", "e1d2", true},
+		{"75-move rule", "4k3/8/8/8/8/8/8/R3K3 w - - 149 100", "a1a2", true},
+		{"74.5 moves", "4k3/8/8/8/8/8/8/R3K3 w - - 148 100", "a1a2", false},
+		{
+			"fivefold repetition",
+			StartFEN,
+			"g1f3 g8f6 f3g1 f6g8 g1f3 g8f6 f3g1 f6g8 g1f3 g8f6 f3g1 f6g8 g1f3 g8f6 f3g1 f6g8",
+			true,
+		},
+		{
+			"fourfold repetition",
+			StartFEN,
+			"g1f3 g8f6 f3g1 f6g8 g1f3 g8f6 f3g1 f6g8 g1f3 g8f6 f3g1 f6g8",
+			false,
+		},
+	}
+
+	for _, test := range tests {
+		p, err := ParseFEN(test.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g := NewGame(p)
+		for _, s := range strings.Fields(test.moves) {
+			q := g.Position()
+			m, err := q.ParseMove(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			g.Move(m)
+		}
+		if got := g.IsOver(); got != test.want {
+			t.Errorf("%s: IsOver(): got %t, want %t", test.name, got, test.want)
+		}
+	}
+}
+
+func TestPosition_IsGameOver(t *testing.T) {
+	tests := []struct {
+		name   string
+		fen    string
+		want   Outcome
+		wantOK bool
+	}{
+		{"start", StartFEN, Outcome{}, false},
+		{"white mates", "3R2k1/5ppp/8/8/8/8/5PPP/6K1 b - - 0 1", Outcome{Termination: Checkmate, Winner: White}, true},
+		{"black mates", "rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/PPPPP2P/RNBQKBNR w KQkq - 1 3", Outcome{Termination: Checkmate, Winner: Black}, true},
+		{"stalemate", "7k/5Q2/6K1/8/8/8/8/8 b - - 0 1", Outcome{Termination: Stalemate}, true},
+		{"insufficient material", "4k3/8/8/8/8/8/8/3NK3 w - - 0 1", Outcome{Termination: InsufficientMaterial}, true},
+		{"75-move rule", "4k3/8/8/8/8/8/8/R3K3 b - - 150 100", Outcome{Termination: SeventyFiveMoveRule}, true},
+		{"74.5 moves", "4k3/8/8/8/8/8/8/R3K3 b - - 149 100", Outcome{}, false},
+		{"checkmate beats the 75-move rule", "3R2k1/5ppp/8/8/8/8/5PPP/6K1 b - - 150 100", Outcome{Termination: Checkmate, Winner: White}, true},
+	}
+
+	for _, test := range tests {
+		p, err := ParseFEN(test.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, ok := p.IsGameOver()
+		if got != test.want || ok != test.wantOK {
+			t.Errorf("%s: IsGameOver(): got %v, %t, want %v, %t", test.name, got, ok, test.want, test.wantOK)
+		}
+	}
+}
+
+func TestPosition_IsSeventyFiveMoveRule(t *testing.T) {
+	tests := []struct {
+		fen  string
+		want bool
+	}{
+		{"4k3/8/8/8/8/8/8/R3K3 b - - 149 100", false},
+		{"4k3/8/8/8/8/8/8/R3K3 b - - 150 100", true},
+		{"4k3/8/8/8/8/8/8/R3K3 b - - 151 101", true},
+	}
+
+	for _, test := range tests {
+		p, err := ParseFEN(test.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := p.IsSeventyFiveMoveRule(); got != test.want {
+			t.Errorf("IsSeventyFiveMoveRule(%q): got %t, want %t", test.fen, got, test.want)
+		}
+	}
+}
+
+func TestGame_Outcome_fivefoldRepetition(t *testing.T) {
+	g := playGame(t, "g1f3 g8f6 f3g1 f6g8 g1f3 g8f6 f3g1 f6g8 g1f3 g8f6 f3g1 f6g8 g1f3 g8f6 f3g1 f6g8")
+	want := Outcome{Termination: FivefoldRepetition}
+	if got, ok := g.Outcome(); got != want || !ok {
+		t.Errorf("Outcome(): got %v, %t, want %v, true", got, ok, want)
+	}
+}
+
+func TestGame_halfmoveClockThresholds(t *testing.T) {
+	tests := []struct {
+		plies     int
+		wantClaim bool
+		wantOver  bool
+	}{
+		{0, false, false},
+		{99, false, false},
+		{100, true, false},
+		{149, true, false},
+		{150, true, true},
+	}
+
+	for _, test := range tests {
+		p, err := ParseFEN(fmt.Sprintf("4k3/8/8/8/8/8/8/R3K3 w - - %d 100", test.plies))
+		if err != nil {
+			t.Fatal(err)
+		}
+		g := NewGame(p)
+		if got := g.CanClaimFiftyMoveRule(); got != test.wantClaim {
+			t.Errorf("halfmove clock %d: CanClaimFiftyMoveRule(): got %t, want %t", test.plies, got, test.wantClaim)
+		}
+		if got := g.IsOver(); got != test.wantOver {
+			t.Errorf("halfmove clock %d: IsOver(): got %t, want %t", test.plies, got, test.wantOver)
+		}
+		// The halfmove clock ends the game, not move generation.
+		if got := len(p.Moves()); got != 15 {
+			t.Errorf("halfmove clock %d: got %d moves, want 15", test.plies, got)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package core
+
+import "math/bits"
+
+// Key is a hash of a position, for use as a key in hash tables like a
+// transposition table.
+//
+// Keys are opaque: their size and how they are computed may change, so they
+// should only be compared for equality or reduced with [Key.Index].
+type Key uint64
+
+// Index returns the index of k in a table with size slots. The size must be a
+// power of two.
+//
+// Index uses Fibonacci hashing: it multiplies k by 2^64 divided by the golden
+// ratio and keeps the high bits. Unlike masking off the low bits, this spreads
+// keys that differ only in their high bits across the table.
+func (k Key) Index(size int) int {
+	const phi = 0x9e3779b97f4a7c15
+	shift := 64 - bits.Len(uint(size-1))
+	return int(uint64(k) * phi >> shift)
+}
+
+// Hash returns the key of p. Positions that are identical for the purpose of
+// detecting repetitions have the same key, and different positions have
+// different keys with high probability.
+//
+// The key is updated incrementally by [Position.Move], so Hash is cheap. It is
+// not updated if the fields of p are changed directly.
+func (p *Position) Hash() Key {
+	return p.hash
+}
+
+// zobrist computes the key of p from scratch.
+func (p *Position) zobrist() Key {
+	// Polyglot keys are Zobrist hashes with suitable properties, so reuse
+	// them for now.
+	return Key(p.PolyglotKey())
+}
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+func TestPosition_Hash(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  string
+		equal bool
+	}{
+		{"transposition", "g1f3 g8f6 b1c3", "b1c3 g8f6 g1f3", true},
+		{"same squares, different turn", "g1f3 g8f6 f3g1", "g1f3 g8f6 f3g1 f6g8", false},
+		{"uncapturable en passant", "e2e4 g8f6 g1f3", "g1f3 g8f6 e2e4", true},
+		{"different pieces", "e2e4", "d2d4", false},
+	}
+
+	for _, test := range tests {
+		a, b := playGame(t, test.a).Position(), playGame(t, test.b).Position()
+		if got := a.Hash() == b.Hash(); got != test.equal {
+			t.Errorf("%s: got equal %t, want %t", test.name, got, test.equal)
+		}
+	}
+}
+
+func TestPosition_Hash_incremental(t *testing.T) {
+	for _, name := range []string{"start", "kiwipete"} {
+		p, _ := TestPosition(name)
+		r := rand.New(rand.NewPCG(1, 2))
+
+		for ply := 0; ply < 300; ply++ {
+			moves := p.Moves()
+			if len(moves) == 0 {
+				break
+			}
+			m := moves[r.IntN(len(moves))]
+			p.Move(m)
+			if got, want := p.Hash(), p.zobrist(); got != want {
+				t.Fatalf("%s, ply %d: Hash() after %v: got %#x, want %#x", name, ply, m, uint64(got), uint64(want))
+			}
+		}
+	}
+}
+
+func TestKey_Index(t *testing.T) {
+	const size = 1 << 10
+	for _, k := range []Key{0, 1, size - 1, size, 0xdeadbeefcafebabe, ^Key(0)} {
+		if got := k.Index(size); got < 0 || got >= size {
+			t.Errorf("Key(%#x).Index(%d): got %d, want in [0, %d)", uint64(k), size, got, size)
+		}
+	}
+	if got := Key(12345).Index(1); got != 0 {
+		t.Errorf("Key(12345).Index(1): got %d, want 0", got)
+	}
+
+	// Keys that differ only in their high bits still spread out.
+	seen := make(map[int]bool)
+	for i := range Key(64) {
+		seen[(i << 56).Index(size)] = true
+	}
+	if len(seen) < 60 {
+		t.Errorf("64 keys differing in their high bits used only %d indexes", len(seen))
+	}
+}
@@ -0,0 +1,184 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+)
+
+// MoveErrorReason describes why a move is illegal.
+type MoveErrorReason uint8
+
+// [MoveErrorReason] constants.
+const (
+	// The from square is empty.
+	NoPieceThere MoveErrorReason = iota + 1
+
+	// The piece on the from square belongs to the other player.
+	NotYourTurn
+
+	// The to square holds a piece of the moving player's own color.
+	WrongColor
+
+	// The piece could make the move on an empty board, but another piece is in
+	// the way.
+	PathBlocked
+
+	// The move is castling, but castling is not allowed.
+	IllegalCastle
+
+	// The move would leave the moving player's king in check.
+	LeavesKingInCheck
+
+	// The piece cannot move that way, or the move promotes incorrectly.
+	InvalidMovement
+)
+
+// String implements [fmt.Stringer].
+func (r MoveErrorReason) String() string {
+	switch r {
+	case NoPieceThere:
+		return "no piece there"
+	case NotYourTurn:
+		return "not your turn"
+	case WrongColor:
+		return "captures own piece"
+	case PathBlocked:
+		return "path blocked"
+	case IllegalCastle:
+		return "illegal castle"
+	case LeavesKingInCheck:
+		return "leaves king in check"
+	case InvalidMovement:
+		return "invalid movement"
+	default:
+		return fmt.Sprintf("MoveErrorReason(%d)", r)
+	}
+}
+
+// MoveError describes an illegal move.
+type MoveError struct {
+	Move   Move
+	Reason MoveErrorReason
+
+	// Hint, if not empty, explains how to write the intended move.
+	Hint string
+}
+
+// Error implements [error].
+func (e *MoveError) Error() string {
+	if e.Hint != "" {
+		return fmt.Sprintf("illegal move %v: %v (%s)", e.Move, e.Reason, e.Hint)
+	}
+	return fmt.Sprintf("illegal move %v: %v", e.Move, e.Reason)
+}
+
+// MoveError returns nil if m is a legal move. Otherwise, it returns a
+// [*MoveError] explaining why m is illegal.
+func (p *Position) MoveError(m Move) error {
+	if p.IsLegal(m) {
+		return nil
+	}
+	e := &MoveError{Move: m, Reason: p.illegalReason(m)}
+	if rook, ok := p.unencodedCastle(m); ok {
+		e.Hint = fmt.Sprintf("castling is encoded as the king moving onto its rook, like NewCastle(%v, %v)", m.From(), rook)
+	}
+	return e
+}
+
+// unencodedCastle returns the square of the castling rook if m is a legal
+// castle written as a plain king move, like E1 to G1, rather than with
+// [NewCastle].
+func (p *Position) unencodedCastle(m Move) (Square, bool) {
+	if m.IsCastleEncoded() || m.IsPromotion() {
+		return 0, false
+	}
+	if piece, ok := p.Board.Piece(m.From()); !ok || piece != NewPiece(p.Turn, King) {
+		return 0, false
+	}
+	rook, ok := p.castlingRook(m.From(), m.To())
+	if !ok || !p.IsLegal(NewCastle(m.From(), rook)) {
+		return 0, false
+	}
+	return rook, true
+}
+
+// illegalReason returns the reason that m, an illegal move, is illegal.
+func (p *Position) illegalReason(m Move) MoveErrorReason {
+	from, to := m.From(), m.To()
+
+	piece, ok := p.Board.Piece(from)
+	if !ok {
+		return NoPieceThere
+	}
+	if piece.Color != p.Turn {
+		return NotYourTurn
+	}
+	// Castling is allowed, but written as a plain king move.
+	if _, ok := p.unencodedCastle(m); ok {
+		return InvalidMovement
+	}
+	if m.IsCastleEncoded() || piece.PieceType == King && isCastlingShape(piece.Color, from, to) {
+		return IllegalCastle
+	}
+	if c, ok := p.Board.PieceColor(to); ok && c == p.Turn {
+		return WrongColor
+	}
+	if slices.Contains(p.pseudoLegalMoves(), m) {
+		return LeavesKingInCheck
+	}
+
+	// Promotions must be made by pawns reaching the last rank, and vice versa.
+	lastRank := Rank8
+	if p.Turn == Black {
+		lastRank = Rank1
+	}
+	mustPromote := piece.PieceType == Pawn && to.Rank() == lastRank
+	if m.IsPromotion() != mustPromote || m.promotion == King {
+		return InvalidMovement
+	}
+
+	if reachesOnEmptyBoard(piece, from, to) {
+		return PathBlocked
+	}
+	return InvalidMovement
+}
+
+// isCastlingShape returns true if a king of color c moving between from and to
+// would be castling.
+func isCastlingShape(c Color, from, to Square) bool {
+	if c == White {
+		return from == E1 && (to == G1 || to == C1)
+	}
+	return from == E8 && (to == G8 || to == C8)
+}
+
+// reachesOnEmptyBoard returns true if p could move between from and to if no
+// other pieces were on the board. Pawn captures are not considered.
+func reachesOnEmptyBoard(p Piece, from, to Square) bool {
+	var targets Bitboard
+	switch p.PieceType {
+	case Pawn:
+		forward, startRank := direction{0, 1}, Rank2
+		if p.Color == Black {
+			forward, startRank = direction{0, -1}, Rank7
+		}
+		if s, ok := from.offset(forward); ok {
+			targets.Set(s)
+			if s, ok := s.offset(forward); ok && from.Rank() == startRank {
+				targets.Set(s)
+			}
+		}
+	case Knight:
+		targets = knightAttacks[from]
+	case Bishop:
+		targets = slidingAttacks(from, 0, bishopDirections)
+	case Rook:
+		targets = slidingAttacks(from, 0, rookDirections)
+	case Queen:
+		targets = slidingAttacks(from, 0, bishopDirections) |
+			slidingAttacks(from, 0, rookDirections)
+	case King:
+		targets = kingAttacks[from]
+	}
+	return targets.Get(to)
+}
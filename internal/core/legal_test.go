@@ -0,0 +1,124 @@
+package core
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPosition_MoveError(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		move Move
+		want MoveErrorReason
+	}{
+		{
+			name: "empty square",
+			fen:  StartFEN,
+			move: NewMove(E4, E5),
+			want: NoPieceThere,
+		},
+		{
+			name: "opponent's piece",
+			fen:  StartFEN,
+			move: NewMove(E7, E5),
+			want: NotYourTurn,
+		},
+		{
+			name: "captures own piece",
+			fen:  StartFEN,
+			move: NewMove(D1, D2),
+			want: WrongColor,
+		},
+		{
+			name: "bishop blocked by own pawn",
+			fen:  StartFEN,
+			move: NewMove(C1, F4),
+			want: PathBlocked,
+		},
+		{
+			name: "double push blocked",
+			fen:  "4k3/8/8/8/8/4n3/4P3/4K3 w - - 0 1",
+			move: NewMove(E2, E4),
+			want: PathBlocked,
+		},
+		{
+			name: "knight moves like a bishop",
+			fen:  StartFEN,
+			move: NewMove(G1, E3),
+			want: InvalidMovement,
+		},
+		{
+			name: "pawn reaches last rank without promoting",
+			fen:  "4k3/P7/8/8/8/8/8/4K3 w - - 0 1",
+			move: NewMove(A7, A8),
+			want: InvalidMovement,
+		},
+		{
+			name: "castles through pieces",
+			fen:  StartFEN,
+			move: NewCastle(E1, H1),
+			want: IllegalCastle,
+		},
+		{
+			name: "castles through check",
+			fen:  "4k3/8/8/8/8/8/5r2/4K2R w K - 0 1",
+			move: NewCastle(E1, H1),
+			want: IllegalCastle,
+		},
+		{
+			name: "castles written as a king move",
+			fen:  "4k3/8/8/8/8/8/8/4K2R w K - 0 1",
+			move: NewMove(E1, G1),
+			want: InvalidMovement,
+		},
+		{
+			name: "pinned knight moves",
+			fen:  "4k3/4r3/8/8/8/8/4N3/4K3 w - - 0 1",
+			move: NewMove(E2, C3),
+			want: LeavesKingInCheck,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := ParseFEN(test.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = p.MoveError(test.move)
+			var moveErr *MoveError
+			if !errors.As(err, &moveErr) {
+				t.Fatalf("MoveError(%v): got %v, want a *MoveError", test.move, err)
+			}
+			if moveErr.Reason != test.want {
+				t.Errorf("MoveError(%v): got reason %v, want %v", test.move, moveErr.Reason, test.want)
+			}
+		})
+	}
+}
+
+func TestPosition_MoveError_hint(t *testing.T) {
+	p, err := ParseFEN("4k3/8/8/8/8/8/8/4K2R w K - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var moveErr *MoveError
+	if err := p.MoveError(NewMove(E1, G1)); !errors.As(err, &moveErr) || moveErr.Hint == "" {
+		t.Errorf("MoveError(e1g1): got %v, want a *MoveError with a hint", err)
+	}
+	if err := p.MoveError(NewMove(E1, E2)); err != nil {
+		t.Errorf("MoveError(e1e2): got %v, want nil", err)
+	}
+	if err := p.MoveError(NewCastle(E1, H1)); err != nil {
+		t.Errorf("MoveError(castle e1h1): got %v, want nil", err)
+	}
+}
+
+func TestPosition_MoveError_legal(t *testing.T) {
+	p := NewPosition()
+	if err := p.MoveError(NewMove(E2, E4)); err != nil {
+		t.Errorf("MoveError(e2e4): got %v, want nil", err)
+	}
+}
@@ -0,0 +1,425 @@
+package core
+
+import (
+	"math/bits"
+	"slices"
+)
+
+// A direction is a file and rank offset between two squares.
+type direction struct {
+	df, dr int
+}
+
+var (
+	knightDirections = [8]direction{
+		{1, 2}, {2, 1}, {2, -1}, {1, -2}, {-1, -2}, {-2, -1}, {-2, 1}, {-1, 2},
+	}
+	kingDirections = [8]direction{
+		{0, 1}, {1, 1}, {1, 0}, {1, -1}, {0, -1}, {-1, -1}, {-1, 0}, {-1, 1},
+	}
+	bishopDirections = [4]direction{{1, 1}, {1, -1}, {-1, -1}, {-1, 1}}
+	rookDirections   = [4]direction{{0, 1}, {1, 0}, {0, -1}, {-1, 0}}
+)
+
+// Precomputed attacks for pieces that do not slide, indexed by [Square].
+var (
+	knightAttacks [64]Bitboard
+	kingAttacks   [64]Bitboard
+
+	// Indexed by [Color] (0 for White, 1 for Black), then by [Square].
+	pawnAttacks [2][64]Bitboard
+)
+
+func init() {
+	for s := A1; s <= H8; s++ {
+		for _, d := range knightDirections {
+			if t, ok := s.offset(d); ok {
+				knightAttacks[s].Set(t)
+			}
+		}
+		for _, d := range kingDirections {
+			if t, ok := s.offset(d); ok {
+				kingAttacks[s].Set(t)
+			}
+		}
+		for _, df := range []int{-1, 1} {
+			if t, ok := s.offset(direction{df, 1}); ok {
+				pawnAttacks[0][s].Set(t)
+			}
+			if t, ok := s.offset(direction{df, -1}); ok {
+				pawnAttacks[1][s].Set(t)
+			}
+		}
+	}
+}
+
+// KnightAttacks returns the squares a knight on s attacks.
+func KnightAttacks(s Square) Bitboard {
+	return knightAttacks[s]
+}
+
+// KingAttacks returns the squares a king on s attacks.
+func KingAttacks(s Square) Bitboard {
+	return kingAttacks[s]
+}
+
+// PawnAttacks returns the squares a pawn of color c on s attacks.
+func PawnAttacks(c Color, s Square) Bitboard {
+	return pawnAttacks[colorIndex(c)][s]
+}
+
+// PawnSetAttacks returns the squares attacked by any of the pawns of color c.
+func PawnSetAttacks(pawns Bitboard, c Color) Bitboard {
+	if c == White {
+		return pawns.NorthWest() | pawns.NorthEast()
+	}
+	return pawns.SouthWest() | pawns.SouthEast()
+}
+
+// BishopAttacks returns the squares a bishop on s attacks, given the occupied
+// squares. Occupied squares are attacked but not passed through.
+func BishopAttacks(s Square, occupied Bitboard) Bitboard {
+	return slidingAttacks(s, occupied, bishopDirections)
+}
+
+// RookAttacks returns the squares a rook on s attacks, given the occupied
+// squares. Occupied squares are attacked but not passed through.
+func RookAttacks(s Square, occupied Bitboard) Bitboard {
+	return slidingAttacks(s, occupied, rookDirections)
+}
+
+// offset returns the square d away from s, if any.
+func (s Square) offset(d direction) (Square, bool) {
+	f, r := int(s.File())+d.df, int(s.Rank())+d.dr
+	if f < 0 || f > 7 || r < 0 || r > 7 {
+		return 0, false
+	}
+	return NewSquare(File(f), Rank(r)), true
+}
+
+// colorIndex returns 0 for White and 1 for Black.
+func colorIndex(c Color) int {
+	if c == White {
+		return 0
+	}
+	return 1
+}
+
+// slidingAttacks returns the squares attacked from s by a piece sliding in the
+// given directions, stopping at the first occupied square in each direction.
+func slidingAttacks(s Square, occupied Bitboard, dirs [4]direction) Bitboard {
+	var b Bitboard
+	for _, d := range dirs {
+		for t, ok := s.offset(d); ok; t, ok = t.offset(d) {
+			b.Set(t)
+			if occupied.Get(t) {
+				break
+			}
+		}
+	}
+	return b
+}
+
+// IsAttacked returns true if any piece of color by attacks s, regardless of
+// whose turn it is.
+func (b *Board) IsAttacked(s Square, by Color) bool {
+	attackers := b.color(by)
+	occupied := b.Occupied()
+
+	// A pawn of color by attacks s if a pawn of the other color on s would
+	// attack it.
+	if pawnAttacks[colorIndex(by.Other())][s]&attackers&b.pieces[Pawn] != 0 {
+		return true
+	}
+	if knightAttacks[s]&attackers&b.pieces[Knight] != 0 {
+		return true
+	}
+	if kingAttacks[s]&attackers&b.pieces[King] != 0 {
+		return true
+	}
+
+	diagonal := attackers & (b.pieces[Bishop] | b.pieces[Queen])
+	if slidingAttacks(s, occupied, bishopDirections)&diagonal != 0 {
+		return true
+	}
+
+	orthogonal := attackers & (b.pieces[Rook] | b.pieces[Queen])
+	return slidingAttacks(s, occupied, rookDirections)&orthogonal != 0
+}
+
+// attackersTo returns the pieces of both colors that attack s, with sliding
+// pieces blocked by occupied.
+func (b *Board) attackersTo(s Square, occupied Bitboard) Bitboard {
+	diagonal := b.pieces[Bishop] | b.pieces[Queen]
+	orthogonal := b.pieces[Rook] | b.pieces[Queen]
+	return pawnAttacks[colorIndex(Black)][s]&b.pieces[Pawn]&b.white |
+		pawnAttacks[colorIndex(White)][s]&b.pieces[Pawn]&b.black |
+		knightAttacks[s]&b.pieces[Knight] |
+		kingAttacks[s]&b.pieces[King] |
+		slidingAttacks(s, occupied, bishopDirections)&diagonal |
+		slidingAttacks(s, occupied, rookDirections)&orthogonal
+}
+
+// InCheck returns true if the king of the player whose turn it is is attacked.
+func (p *Position) InCheck() bool {
+	k, ok := p.Board.KingSquare(p.Turn)
+	return ok && p.Board.IsAttacked(k, p.Turn.Other())
+}
+
+// IsCheckmate returns true if the player whose turn it is is in check and has
+// no legal moves.
+func (p *Position) IsCheckmate() bool {
+	return p.InCheck() && len(p.Moves()) == 0
+}
+
+// IsStalemate returns true if the player whose turn it is is not in check but
+// has no legal moves.
+func (p *Position) IsStalemate() bool {
+	return !p.InCheck() && len(p.Moves()) == 0
+}
+
+// Moves returns all legal moves.
+//
+// It ignores the halfmove clock: a game drawn by the 75-move rule, after 150
+// plies without a capture or pawn advance, still has moves. Use
+// [Position.IsGameOver] to check whether the game has ended.
+func (p *Position) Moves() []Move {
+	return p.AppendMoves(nil)
+}
+
+// AppendMoves appends all legal moves to moves and returns the extended slice.
+// Reusing a slice with spare capacity avoids allocating.
+func (p *Position) AppendMoves(moves []Move) []Move {
+	n := len(moves)
+	moves = p.appendPseudoLegalMoves(moves)
+
+	// Filter in place, keeping the moves that don't leave the king in check.
+	legal := moves[:n]
+	for _, m := range moves[n:] {
+		q := *p
+		q.Move(m)
+		if k, ok := q.Board.KingSquare(p.Turn); ok && q.Board.IsAttacked(k, q.Turn) {
+			continue
+		}
+		legal = append(legal, m)
+	}
+	return legal
+}
+
+// PlayMoves returns the legal moves worth considering in play: all of them
+// except promotions to a rook or bishop, which a queen promotion almost always
+// beats. Unlike [Position.Moves], it doesn't suit perft.
+func (p *Position) PlayMoves() []Move {
+	return p.AppendPlayMoves(nil)
+}
+
+// AppendPlayMoves appends the moves returned by [Position.PlayMoves] to moves
+// and returns the extended slice.
+func (p *Position) AppendPlayMoves(moves []Move) []Move {
+	n := len(moves)
+	moves = p.AppendMoves(moves)
+
+	// A promotion to a queen is legal whenever one to a rook or bishop is, so
+	// this never removes the last legal move.
+	kept := slices.DeleteFunc(moves[n:], func(m Move) bool {
+		pt, ok := m.PromotionTo()
+		return ok && (pt == Rook || pt == Bishop)
+	})
+	return moves[:n+len(kept)]
+}
+
+// IsForced returns the only legal move and true if there is exactly one legal
+// move. Otherwise, it returns false.
+func (p *Position) IsForced() (Move, bool) {
+	var buf [2]Move
+	moves := p.AppendMoves(buf[:0])
+	if len(moves) != 1 {
+		return Move{}, false
+	}
+	return moves[0], true
+}
+
+// LegalTargets returns the squares that any legal move lands on. For castling
+// moves, this is the square the king lands on.
+func (p *Position) LegalTargets() Bitboard {
+	var b Bitboard
+	for _, m := range p.Moves() {
+		b.Set(m.target())
+	}
+	return b
+}
+
+// LegalTargetsFrom returns the squares that legal moves from s land on. If s
+// is empty or holds a piece of the player whose turn it isn't, it returns the
+// empty bitboard.
+func (p *Position) LegalTargetsFrom(s Square) Bitboard {
+	var b Bitboard
+	for _, m := range p.Moves() {
+		if m.From() == s {
+			b.Set(m.target())
+		}
+	}
+	return b
+}
+
+// IsLegal returns true if m is a legal move.
+func (p *Position) IsLegal(m Move) bool {
+	return slices.Contains(p.Moves(), m)
+}
+
+// pseudoLegalMoves returns all moves that follow the movement rules of each
+// piece, including moves that leave the player's king in check.
+//
+// Castling moves are only returned if the king does not castle out of or
+// through check.
+func (p *Position) pseudoLegalMoves() []Move {
+	return p.appendPseudoLegalMoves(nil)
+}
+
+// appendPseudoLegalMoves appends the moves returned by
+// [Position.pseudoLegalMoves] to moves and returns the extended slice.
+func (p *Position) appendPseudoLegalMoves(moves []Move) []Move {
+	us := p.Turn
+	own := p.Board.color(us)
+	enemy := p.Board.color(us.Other())
+	occupied := own | enemy
+
+	moves = p.appendPawnMoves(moves)
+	for from := own &^ p.Board.Pieces(NewPiece(us, Pawn)); from != 0; from &= from - 1 {
+		s := Square(bits.TrailingZeros64(uint64(from)))
+		pt, _ := p.Board.PieceType(s)
+
+		var targets Bitboard
+		switch pt {
+		case Knight:
+			targets = knightAttacks[s]
+		case Bishop:
+			targets = slidingAttacks(s, occupied, bishopDirections)
+		case Rook:
+			targets = slidingAttacks(s, occupied, rookDirections)
+		case Queen:
+			targets = slidingAttacks(s, occupied, bishopDirections) |
+				slidingAttacks(s, occupied, rookDirections)
+		case King:
+			targets = kingAttacks[s]
+		}
+
+		for to := targets &^ own; to != 0; to &= to - 1 {
+			moves = append(moves, NewMove(s, Square(bits.TrailingZeros64(uint64(to)))))
+		}
+	}
+
+	return p.appendCastlingMoves(moves)
+}
+
+// appendPawnMoves appends the pseudo-legal moves of the pawns of the player
+// whose turn it is.
+func (p *Position) appendPawnMoves(moves []Move) []Move {
+	us := p.Turn
+	pawns := p.Board.Pieces(NewPiece(us, Pawn))
+	empty := ^p.Board.Occupied()
+
+	// The distance from a pushed pawn's square back to where it started.
+	back, lastRank := -8, Rank8
+	if us == Black {
+		back, lastRank = 8, Rank1
+	}
+
+	// Pushes.
+	for to := pawnSinglePushes(pawns, empty, us); to != 0; to &= to - 1 {
+		s := Square(bits.TrailingZeros64(uint64(to)))
+		moves = appendPawnMove(moves, s+Square(back), s, lastRank)
+	}
+	for to := pawnDoublePushes(pawns, empty, us); to != 0; to &= to - 1 {
+		s := Square(bits.TrailingZeros64(uint64(to)))
+		moves = append(moves, NewMove(s+Square(2*back), s))
+	}
+
+	// Captures, including en passant.
+	targets := p.Board.color(us.Other())
+	if ep, ok := p.EnPassant.Square(); ok {
+		targets.Set(ep)
+	}
+	for from := pawns; from != 0; from &= from - 1 {
+		s := Square(bits.TrailingZeros64(uint64(from)))
+		for to := pawnAttacks[colorIndex(us)][s] & targets; to != 0; to &= to - 1 {
+			moves = appendPawnMove(moves, s, Square(bits.TrailingZeros64(uint64(to))), lastRank)
+		}
+	}
+
+	return moves
+}
+
+// appendPawnMove appends the pawn move from one square to another, or all four
+// promotions if to is on lastRank.
+func appendPawnMove(moves []Move, from, to Square, lastRank Rank) []Move {
+	if to.Rank() != lastRank {
+		return append(moves, NewMove(from, to))
+	}
+	for _, pt := range []PieceType{Queen, Rook, Bishop, Knight} {
+		moves = append(moves, NewPromotion(from, to, pt))
+	}
+	return moves
+}
+
+// pawnSinglePushes returns the squares that pawns of color c can move to by
+// advancing one square onto an empty square.
+func pawnSinglePushes(pawns, empty Bitboard, c Color) Bitboard {
+	if c == White {
+		return pawns.North() & empty
+	}
+	return pawns.South() & empty
+}
+
+// pawnDoublePushes returns the squares that pawns of color c can move to by
+// advancing two squares from their starting rank, across an empty square onto
+// another.
+func pawnDoublePushes(pawns, empty Bitboard, c Color) Bitboard {
+	single := pawnSinglePushes(pawns, empty, c)
+	if c == White {
+		return pawnSinglePushes(single&Rank3.Bitboard(), empty, c) & Rank4.Bitboard()
+	}
+	return pawnSinglePushes(single&Rank6.Bitboard(), empty, c) & Rank5.Bitboard()
+}
+
+// A castlingMove describes the squares involved in castling.
+type castlingMove struct {
+	right    Castling
+	king     Square
+	rook     Square
+	empty    Bitboard // Must be empty.
+	crossing Square   // The king must not be attacked here.
+}
+
+var castlingMoves = [4]castlingMove{
+	{WhiteOO, E1, H1, F1.Bitboard() | G1.Bitboard(), F1},
+	{WhiteOOO, E1, A1, B1.Bitboard() | C1.Bitboard() | D1.Bitboard(), D1},
+	{BlackOO, E8, H8, F8.Bitboard() | G8.Bitboard(), F8},
+	{BlackOOO, E8, A8, B8.Bitboard() | C8.Bitboard() | D8.Bitboard(), D8},
+}
+
+// appendCastlingMoves appends the castling moves of the player whose turn it
+// is, excluding those that castle out of or through check.
+func (p *Position) appendCastlingMoves(moves []Move) []Move {
+	us, them := p.Turn, p.Turn.Other()
+	occupied := p.Board.Occupied()
+	for _, cm := range castlingMoves {
+		if !p.Castling.GetAll(cm.right) {
+			continue
+		}
+		if king, ok := p.Board.Piece(cm.king); !ok || king != NewPiece(us, King) {
+			continue
+		}
+		if rook, ok := p.Board.Piece(cm.rook); !ok || rook != NewPiece(us, Rook) {
+			continue
+		}
+		if cm.empty&occupied != 0 {
+			continue
+		}
+		if p.Board.IsAttacked(cm.king, them) || p.Board.IsAttacked(cm.crossing, them) {
+			continue
+		}
+		moves = append(moves, NewCastle(cm.king, cm.rook))
+	}
+	return moves
+}
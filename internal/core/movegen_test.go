@@ -0,0 +1,495 @@
+package core
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPosition_IsLegal(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		move Move
+		want bool
+	}{
+		{
+			name: "pawn push",
+			fen:  StartFEN,
+			move: NewMove(E2, E4),
+			want: true,
+		},
+		{
+			name: "knight jump",
+			fen:  StartFEN,
+			move: NewMove(G1, F3),
+			want: true,
+		},
+		{
+			name: "pawn push too far",
+			fen:  StartFEN,
+			move: NewMove(E2, E5),
+			want: false,
+		},
+		{
+			name: "king captures unprotected rook",
+			fen:  "4k3/8/8/8/8/8/3r4/4K3 w - - 0 1",
+			move: NewMove(E1, D2),
+			want: true,
+		},
+		{
+			name: "king moves into check",
+			fen:  "4k3/8/8/8/8/8/3r4/4K3 w - - 0 1",
+			move: NewMove(E1, E2),
+			want: false,
+		},
+		{
+			name: "pinned knight moves",
+			fen:  "4k3/4r3/8/8/8/8/4N3/4K3 w - - 0 1",
+			move: NewMove(E2, C3),
+			want: false,
+		},
+		{
+			name: "wrong player moves",
+			fen:  StartFEN,
+			move: NewMove(E7, E5),
+			want: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := ParseFEN(test.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := p.IsLegal(test.move); got != test.want {
+				t.Errorf("IsLegal(%v): got %v, want %v", test.move, got, test.want)
+			}
+		})
+	}
+}
+
+func TestPosition_Moves_enPassant(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		want bool
+	}{
+		{
+			name: "capture allowed",
+			fen:  "8/8/8/K1Pp4/8/8/8/k7 w - d6 0 1",
+			want: true,
+		},
+		{
+			// Capturing removes both pawns from the fifth rank, exposing the
+			// king to the rook.
+			name: "capture exposes king along rank",
+			fen:  "8/8/8/K1Pp3r/8/8/8/k7 w - d6 0 1",
+			want: false,
+		},
+		{
+			name: "capture exposes king along rank for black",
+			fen:  "K7/8/8/8/R2pP2k/8/8/8 b - e3 0 1",
+			want: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := ParseFEN(test.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ep, _ := p.EnPassant.Square()
+
+			got := false
+			for _, m := range p.Moves() {
+				if m.To() == ep {
+					got = true
+				}
+			}
+			if got != test.want {
+				t.Errorf("en passant capture generated: got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPosition_Moves_pawns(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		want int
+	}{
+		{"start", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", 16},
+		{"start for black", "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1", 16},
+		{"blocked", "k7/8/8/8/8/4p3/4P3/K7 w - - 0 1", 0},
+		{"double push blocked", "k7/8/8/8/4p3/8/4P3/K7 w - - 0 1", 1},
+		{"captures", "k7/8/8/8/8/3p1p2/4P3/K7 w - - 0 1", 4},
+		{"en passant", "k7/8/8/3pP3/8/8/8/K7 w - d6 0 1", 2},
+		{"promotion", "k7/4P3/8/8/8/8/8/K7 w - - 0 1", 4},
+		{"promotion with capture", "k2r4/4P3/8/8/8/8/8/K7 w - - 0 1", 8},
+		{"promotion for black", "k7/8/8/8/8/8/1p6/4K3 b - - 0 1", 4},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := ParseFEN(test.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := 0
+			for _, m := range p.Moves() {
+				if pt, _ := p.Board.PieceType(m.From()); pt == Pawn {
+					got++
+				}
+			}
+			if got != test.want {
+				t.Errorf("pawn moves: got %d, want %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPosition_Moves_castling(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		want []Move
+	}{
+		{"white", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", []Move{NewCastle(E1, H1), NewCastle(E1, A1)}},
+		{"black", "r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1", []Move{NewCastle(E8, H8), NewCastle(E8, A8)}},
+		{"kingside only", "r3k2r/8/8/8/8/8/8/R3K2R w Kkq - 0 1", []Move{NewCastle(E1, H1)}},
+		{"queenside only", "r3k2r/8/8/8/8/8/8/R3K2R b KQq - 0 1", []Move{NewCastle(E8, A8)}},
+		{"no rights", "r3k2r/8/8/8/8/8/8/R3K2R w - - 0 1", nil},
+		{"blocked", "r3k2r/8/8/8/8/8/8/RN2K1NR w KQkq - 0 1", nil},
+		{"blocked by enemy", "r3k2r/8/8/8/8/8/8/R1b1K1nR w KQkq - 0 1", nil},
+		{"rook passes attacked square", "1r2k2r/8/8/8/8/8/8/R3K2R w KQk - 0 1", []Move{NewCastle(E1, H1), NewCastle(E1, A1)}},
+		{"through check", "4kr2/8/8/8/8/8/8/R3K2R w KQ - 0 1", []Move{NewCastle(E1, A1)}},
+		{"into check", "4k1r1/8/8/8/8/8/8/R3K2R w KQ - 0 1", []Move{NewCastle(E1, A1)}},
+		{"out of check", "4r1k1/8/8/8/8/8/8/R3K2R w KQ - 0 1", nil},
+		{"missing rook", "4k3/8/8/8/8/8/8/4K2R w KQ - 0 1", []Move{NewCastle(E1, H1)}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := ParseFENLenient(test.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []Move
+			for _, m := range p.Moves() {
+				if m.IsCastleEncoded() {
+					got = append(got, m)
+				}
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("castling moves: got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPosition_AppendMoves(t *testing.T) {
+	p := NewPosition()
+	prefix := NewMove(A1, A2)
+
+	got := p.AppendMoves([]Move{prefix})
+	if got[0] != prefix {
+		t.Errorf("AppendMoves: got %v first, want prefix %v", got[0], prefix)
+	}
+	if want := p.Moves(); !slices.Equal(got[1:], want) {
+		t.Errorf("AppendMoves: got %v, want %v", got[1:], want)
+	}
+
+	buf := make([]Move, 0, 256)
+	allocs := testing.AllocsPerRun(100, func() {
+		buf = p.AppendMoves(buf[:0])
+	})
+	if allocs != 0 {
+		t.Errorf("AppendMoves with spare capacity: got %v allocations, want 0", allocs)
+	}
+}
+
+func BenchmarkPosition_Moves(b *testing.B) {
+	p := NewPosition()
+	for b.Loop() {
+		p.Moves()
+	}
+}
+
+func BenchmarkPosition_Moves_kiwipete(b *testing.B) {
+	p, _ := TestPosition("kiwipete")
+	for b.Loop() {
+		p.Moves()
+	}
+}
+
+func BenchmarkPosition_AppendMoves(b *testing.B) {
+	p := NewPosition()
+	buf := make([]Move, 0, 256)
+	for b.Loop() {
+		buf = p.AppendMoves(buf[:0])
+	}
+}
+
+func TestPosition_PlayMoves(t *testing.T) {
+	p, err := ParseFEN("k7/4P3/8/8/8/8/8/K7 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		got  []Move
+		want []PieceType
+	}{
+		{"Moves", p.Moves(), []PieceType{Queen, Rook, Bishop, Knight}},
+		{"PlayMoves", p.PlayMoves(), []PieceType{Queen, Knight}},
+	}
+
+	for _, test := range tests {
+		var got []PieceType
+		for _, m := range test.got {
+			if pt, ok := m.PromotionTo(); ok {
+				got = append(got, pt)
+			}
+		}
+		if !slices.Equal(got, test.want) {
+			t.Errorf("%s: got promotions to %v, want %v", test.name, got, test.want)
+		}
+	}
+
+	prefix := NewMove(A1, A2)
+	if got := p.AppendPlayMoves([]Move{prefix}); got[0] != prefix || len(got) != len(p.PlayMoves())+1 {
+		t.Errorf("AppendPlayMoves: got %v, want %v after %v", got, p.PlayMoves(), prefix)
+	}
+}
+
+func TestPosition_IsForced(t *testing.T) {
+	tests := []struct {
+		name   string
+		fen    string
+		want   Move
+		forced bool
+	}{
+		// The rook checks the king, which can only step off the back rank.
+		{"single reply to check", "R5k1/5p1p/8/8/8/8/8/6K1 b - - 0 1", NewMove(G8, G7), true},
+		{"many moves", StartFEN, Move{}, false},
+		{"checkmate", "3R2k1/5ppp/8/8/8/8/5PPP/6K1 b - - 0 1", Move{}, false},
+	}
+
+	for _, test := range tests {
+		p, err := ParseFEN(test.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, forced := p.IsForced()
+		if got != test.want || forced != test.forced {
+			t.Errorf("%s: IsForced(): got %v, %t, want %v, %t", test.name, got, forced, test.want, test.forced)
+		}
+	}
+}
+
+func TestAttacks(t *testing.T) {
+	// The starting position's pawns on ranks 2 and 7.
+	occupied := Rank2.Bitboard() | Rank7.Bitboard()
+
+	tests := []struct {
+		name string
+		got  Bitboard
+		want []Square
+	}{
+		{"KnightAttacks(B1)", KnightAttacks(B1), []Square{A3, C3, D2}},
+		{"KnightAttacks(H8)", KnightAttacks(H8), []Square{F7, G6}},
+		{"KingAttacks(A1)", KingAttacks(A1), []Square{A2, B1, B2}},
+		{"PawnAttacks(White, E4)", PawnAttacks(White, E4), []Square{D5, F5}},
+		{"PawnAttacks(Black, A7)", PawnAttacks(Black, A7), []Square{B6}},
+		{"BishopAttacks(C1)", BishopAttacks(C1, occupied), []Square{B2, D2}},
+		{"RookAttacks(D4)", RookAttacks(D4, occupied), []Square{
+			D2, D3, D5, D6, D7, A4, B4, C4, E4, F4, G4, H4,
+		}},
+	}
+
+	for _, test := range tests {
+		var want Bitboard
+		for _, s := range test.want {
+			want.Set(s)
+		}
+		if test.got != want {
+			t.Errorf("%s: got %#x, want %#x", test.name, uint64(test.got), uint64(want))
+		}
+	}
+}
+
+func TestBoard_IsAttacked(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		s    Square
+		by   Color
+		want bool
+	}{
+		{"white pawn", "k7/8/8/8/8/8/4P3/K7 w - - 0 1", D3, White, true},
+		{"white pawn, other diagonal", "k7/8/8/8/8/8/4P3/K7 w - - 0 1", F3, White, true},
+		{"white pawn ahead", "k7/8/8/8/8/8/4P3/K7 w - - 0 1", E3, White, false},
+		{"white pawn behind", "k7/8/8/8/8/8/4P3/K7 w - - 0 1", D1, White, false},
+		{"black pawn", "k7/4p3/8/8/8/8/8/K7 w - - 0 1", D6, Black, true},
+		{"black pawn behind", "k7/4p3/8/8/8/8/8/K7 w - - 0 1", F8, Black, false},
+		{"pawn on the edge", "4k3/8/8/8/8/8/P7/4K3 w - - 0 1", H4, White, false},
+		{"knight fork, king", "4k3/8/8/8/8/2q2n2/8/4K3 w - - 0 1", E1, Black, true},
+		{"knight fork, rook", "4k3/8/8/8/3n4/8/8/R3K3 w - - 0 1", C2, Black, true},
+		{"knight fork, other target", "4k3/8/8/8/3n4/8/8/R3K3 w - - 0 1", E2, Black, true},
+		{"knight", "4k3/8/8/8/3n4/8/8/R3K3 w - - 0 1", D2, Black, false},
+		{"king", "4k3/8/8/8/8/8/8/4K3 w - - 0 1", D2, White, true},
+		{"rook", "4k3/8/8/8/8/8/8/R3K3 w - - 0 1", A8, White, true},
+		{"rook blocked", "4k3/8/8/8/P7/8/8/R3K3 w - - 0 1", A8, White, false},
+		{"rook blocked by enemy", "4k3/8/8/8/p7/8/8/R3K3 w - - 0 1", A8, White, false},
+		{"rook attacks blocker", "4k3/8/8/8/p7/8/8/R3K3 w - - 0 1", A4, White, true},
+		{"bishop", "4k3/8/8/8/8/8/8/2B1K3 w - - 0 1", H6, White, true},
+		{"queen", "4k3/8/8/8/8/8/8/3QK3 w - - 0 1", D8, White, true},
+		{"turn does not matter", "4k3/8/8/8/8/8/8/R3K3 b - - 0 1", A8, White, true},
+	}
+
+	for _, test := range tests {
+		p, err := ParseFEN(test.fen)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if got := p.Board.IsAttacked(test.s, test.by); got != test.want {
+			t.Errorf("%s: IsAttacked(%v, %v): got %v, want %v", test.name, test.s, test.by, got, test.want)
+		}
+	}
+}
+
+func TestAttacks_count(t *testing.T) {
+	tests := []struct {
+		name string
+		got  Bitboard
+		want int
+	}{
+		{"KnightAttacks(D4)", KnightAttacks(D4), 8},
+		{"KnightAttacks(A1)", KnightAttacks(A1), 2},
+		{"KnightAttacks(H8)", KnightAttacks(H8), 2},
+		{"KnightAttacks(B1)", KnightAttacks(B1), 3},
+		{"KnightAttacks(A4)", KnightAttacks(A4), 4},
+		{"KingAttacks(E5)", KingAttacks(E5), 8},
+		{"KingAttacks(A1)", KingAttacks(A1), 3},
+		{"KingAttacks(H8)", KingAttacks(H8), 3},
+		{"KingAttacks(E1)", KingAttacks(E1), 5},
+	}
+
+	for _, test := range tests {
+		if got := test.got.Count(); got != test.want {
+			t.Errorf("%s: got %d squares, want %d", test.name, got, test.want)
+		}
+	}
+}
+
+func TestPawnSetAttacks(t *testing.T) {
+	tests := []struct {
+		name  string
+		pawns Bitboard
+		c     Color
+		want  Bitboard
+	}{
+		{"start", Rank2.Bitboard(), White, Rank3.Bitboard()},
+		{"start for black", Rank7.Bitboard(), Black, Rank6.Bitboard()},
+		{"a-file", A2.Bitboard(), White, B3.Bitboard()},
+		{"h-file", H2.Bitboard(), White, G3.Bitboard()},
+		{"a-file for black", A7.Bitboard(), Black, B6.Bitboard()},
+		{"h-file for black", H7.Bitboard(), Black, G6.Bitboard()},
+		{"none", 0, White, 0},
+	}
+
+	for _, test := range tests {
+		if got := PawnSetAttacks(test.pawns, test.c); got != test.want {
+			t.Errorf("PawnSetAttacks(%s): got %#x, want %#x", test.name, uint64(got), uint64(test.want))
+		}
+	}
+}
+
+func TestPosition_LegalTargets(t *testing.T) {
+	p := NewPosition()
+
+	tests := []struct {
+		name string
+		got  Bitboard
+		want Bitboard
+	}{
+		{"LegalTargetsFrom(G1)", p.LegalTargetsFrom(G1), F3.Bitboard() | H3.Bitboard()},
+		{"LegalTargetsFrom(E2)", p.LegalTargetsFrom(E2), E3.Bitboard() | E4.Bitboard()},
+		{"LegalTargetsFrom(E1)", p.LegalTargetsFrom(E1), 0},
+		{"LegalTargetsFrom(E7)", p.LegalTargetsFrom(E7), 0},
+		{"LegalTargetsFrom(E4)", p.LegalTargetsFrom(E4), 0},
+		{"LegalTargets()", p.LegalTargets(), Rank3.Bitboard() | Rank4.Bitboard()},
+	}
+
+	for _, test := range tests {
+		if test.got != test.want {
+			t.Errorf("%s: got %#x, want %#x", test.name, uint64(test.got), uint64(test.want))
+		}
+	}
+}
+
+func TestPawnPushes(t *testing.T) {
+	p := NewPosition()
+	empty := ^p.Board.Occupied()
+	white := p.Board.Pieces(NewPiece(White, Pawn))
+	black := p.Board.Pieces(NewPiece(Black, Pawn))
+
+	// A pawn on e5 blocks the double push of black's e-pawn.
+	blocked := p
+	blocked.Board.Set(NewPiece(White, Pawn), E5)
+	blockedEmpty := ^blocked.Board.Occupied()
+
+	tests := []struct {
+		name string
+		got  Bitboard
+		want Bitboard
+	}{
+		{"pawnSinglePushes(white)", pawnSinglePushes(white, empty, White), Rank3.Bitboard()},
+		{"pawnDoublePushes(white)", pawnDoublePushes(white, empty, White), Rank4.Bitboard()},
+		{"pawnSinglePushes(black)", pawnSinglePushes(black, empty, Black), Rank6.Bitboard()},
+		{"pawnDoublePushes(black)", pawnDoublePushes(black, empty, Black), Rank5.Bitboard()},
+		{"pawnDoublePushes(black, blocked)", pawnDoublePushes(black, blockedEmpty, Black), Rank5.Bitboard() &^ E5.Bitboard()},
+	}
+
+	for _, test := range tests {
+		if test.got != test.want {
+			t.Errorf("%s: got %#x, want %#x", test.name, uint64(test.got), uint64(test.want))
+		}
+	}
+}
+
+func TestPosition_checkAndMate(t *testing.T) {
+	tests := []struct {
+		name                                    string
+		fen                                     string
+		wantCheck, wantCheckmate, wantStalemate bool
+	}{
+		{"start", StartFEN, false, false, false},
+		{"check", "4k3/8/8/8/8/8/8/R3K2r w - - 0 1", true, false, false},
+		{"back-rank mate", "3R2k1/5ppp/8/8/8/8/5PPP/6K1 b - - 0 1", true, true, false},
+		{"smothered mate", "6rk/5Npp/8/8/8/8/8/6K1 b - - 0 1", true, true, false},
+		{"fool's mate", "rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/PPPPP2P/RNBQKBNR w KQkq - 1 3", true, true, false},
+		{"stalemate", "7k/5Q2/6K1/8/8/8/8/8 b - - 0 1", false, false, true},
+		{"stalemate with a blocked pawn", "8/8/8/8/8/6k1/6p1/6K1 w - - 0 1", false, false, true},
+	}
+
+	for _, test := range tests {
+		p, err := ParseFEN(test.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := p.InCheck(); got != test.wantCheck {
+			t.Errorf("%s: InCheck(): got %t, want %t", test.name, got, test.wantCheck)
+		}
+		if got := p.IsCheckmate(); got != test.wantCheckmate {
+			t.Errorf("%s: IsCheckmate(): got %t, want %t", test.name, got, test.wantCheckmate)
+		}
+		if got := p.IsStalemate(); got != test.wantStalemate {
+			t.Errorf("%s: IsStalemate(): got %t, want %t", test.name, got, test.wantStalemate)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package core
+
+// Perft returns the number of leaf nodes in the tree of legal moves from p, to
+// the given depth in plies.
+//
+// It's mostly useful for verifying move generation against known results.
+func Perft(p Position, depth int) uint64 {
+	return PerftVisit(p, depth, nil)
+}
+
+// PerftVisit is like [Perft], but it also calls visit at each leaf node with
+// the moves leading there from p. If visit is nil, it is not called.
+//
+// The path passed to visit is only valid during the call; visit must copy it
+// to keep it.
+func PerftVisit(p Position, depth int, visit func(path []Move)) uint64 {
+	path := make([]Move, 0, depth)
+	buf := make([]Move, 0, perftMovesPerPly*max(depth, 1))
+	return perft(&p, depth, path, buf, visit)
+}
+
+// Divide is like [PerftDivide], but it returns the counts keyed by move in UCI
+// long algebraic notation, like "e2e4", for diffing against the output of
+// another engine.
+//
+// If depth is less than 1, Divide returns nil.
+func Divide(p Position, depth int) map[string]uint64 {
+	counts := PerftDivide(p, depth)
+	if counts == nil {
+		return nil
+	}
+	m := make(map[string]uint64, len(counts))
+	for _, c := range counts {
+		m[c.Move.String()] = c.Nodes
+	}
+	return m
+}
+
+// perftMovesPerPly is room for the legal moves of a typical position. Positions
+// with more moves still work, but allocate.
+const perftMovesPerPly = 64
+
+// A PerftCount is the number of leaf nodes below a move.
+type PerftCount struct {
+	Move  Move
+	Nodes uint64
+}
+
+// PerftDivide is like [Perft], but it returns the number of leaf nodes below
+// each legal move from p, in the order [Position.Moves] returns them. This
+// breakdown helps locate bugs by comparing against another move generator.
+//
+// If depth is less than 1, PerftDivide returns nil.
+func PerftDivide(p Position, depth int) []PerftCount {
+	if depth < 1 {
+		return nil
+	}
+	var counts []PerftCount
+	for _, m := range p.Moves() {
+		q := p
+		q.Move(m)
+		counts = append(counts, PerftCount{Move: m, Nodes: Perft(q, depth-1)})
+	}
+	return counts
+}
+
+// perft counts the leaf nodes below p. The legal moves at each ply are
+// appended to buf, and deeper plies use the capacity left over, so that the
+// whole walk shares one allocation.
+func perft(p *Position, depth int, path, buf []Move, visit func([]Move)) uint64 {
+	if depth <= 0 {
+		if visit != nil {
+			visit(path)
+		}
+		return 1
+	}
+
+	moves := p.AppendMoves(buf[:0])
+
+	// Without a visitor, the leaves need not be expanded.
+	if depth == 1 && visit == nil {
+		return uint64(len(moves))
+	}
+
+	var n uint64
+	for _, m := range moves {
+		q := *p
+		q.Move(m)
+		n += perft(&q, depth-1, append(path, m), moves[len(moves):], visit)
+	}
+	return n
+}
+
+// MoveCountsByPiece returns the number of legal moves made by each type of
+// piece. Piece types without legal moves are omitted. Castling moves count as
+// king moves.
+//
+// Like [PerftDivide], it helps narrow down bugs in move generation.
+func (p *Position) MoveCountsByPiece() map[PieceType]int {
+	counts := make(map[PieceType]int)
+	for _, m := range p.Moves() {
+		pt, _ := p.Board.PieceType(m.From())
+		counts[pt]++
+	}
+	return counts
+}
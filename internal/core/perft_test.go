@@ -0,0 +1,175 @@
+package core
+
+import (
+	"bufio"
+	"maps"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestPerft(t *testing.T) {
+	f, err := os.Open("testdata/perft.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Split(text, ";")
+		if len(fields) != 3 {
+			t.Fatalf("line %d: got %d fields, want 3", line, len(fields))
+		}
+		fen := strings.TrimSpace(fields[0])
+		depth, err := strconv.Atoi(strings.TrimSpace(fields[1]))
+		if err != nil {
+			t.Fatalf("line %d: invalid depth: %v", line, err)
+		}
+		want, err := strconv.ParseUint(strings.TrimSpace(fields[2]), 10, 64)
+		if err != nil {
+			t.Fatalf("line %d: invalid node count: %v", line, err)
+		}
+
+		// The deepest searches take a while.
+		if testing.Short() && want > 100000 {
+			continue
+		}
+
+		p, err := ParseFEN(fen)
+		if err != nil {
+			t.Fatalf("line %d: %v", line, err)
+		}
+		if got := Perft(p, depth); got != want {
+			t.Errorf("line %d: Perft(%q, %d): got %d, want %d", line, fen, depth, got, want)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPerftVisit(t *testing.T) {
+	const depth = 3
+
+	p := NewPosition()
+	want := Perft(p, depth)
+
+	var (
+		calls uint64
+		seen  = make(map[[depth]Move]bool)
+	)
+	got := PerftVisit(p, depth, func(path []Move) {
+		calls++
+		if len(path) != depth {
+			t.Fatalf("got path %v with %d moves, want %d", path, len(path), depth)
+		}
+		seen[[depth]Move(path)] = true
+	})
+
+	if got != want {
+		t.Errorf("PerftVisit: got %d, want %d", got, want)
+	}
+	if calls != want {
+		t.Errorf("visitor called %d times, want %d", calls, want)
+	}
+	if uint64(len(seen)) != want {
+		t.Errorf("visitor saw %d distinct paths, want %d", len(seen), want)
+	}
+}
+
+func TestPerftDivide(t *testing.T) {
+	const depth = 3
+
+	p := NewPosition()
+	counts := PerftDivide(p, depth)
+
+	if got, want := len(counts), len(p.Moves()); got != want {
+		t.Fatalf("PerftDivide: got %d moves, want %d", got, want)
+	}
+
+	var total uint64
+	for _, c := range counts {
+		total += c.Nodes
+		// Every first move for White allows 20 replies, so the counts differ
+		// only in White's second moves.
+		if c.Move == NewMove(E2, E4) && c.Nodes != 600 {
+			t.Errorf("PerftDivide: got %d nodes below e2e4, want 600", c.Nodes)
+		}
+	}
+	if want := Perft(p, depth); total != want {
+		t.Errorf("PerftDivide: got %d nodes in total, want %d", total, want)
+	}
+
+	if got := PerftDivide(p, 0); got != nil {
+		t.Errorf("PerftDivide at depth 0: got %v, want nil", got)
+	}
+}
+
+func TestDivide(t *testing.T) {
+	const depth = 3
+
+	p := NewPosition()
+	got := Divide(p, depth)
+
+	want := map[string]uint64{
+		"a2a3": 380, "b2b3": 420, "c2c3": 420, "d2d3": 539,
+		"e2e3": 599, "f2f3": 380, "g2g3": 420, "h2h3": 380,
+		"a2a4": 420, "b2b4": 421, "c2c4": 441, "d2d4": 560,
+		"e2e4": 600, "f2f4": 401, "g2g4": 421, "h2h4": 420,
+		"b1a3": 400, "b1c3": 440, "g1f3": 440, "g1h3": 400,
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("Divide(start, %d): got %v, want %v", depth, got, want)
+	}
+
+	var total uint64
+	for _, n := range got {
+		total += n
+	}
+	if want := Perft(p, depth); total != want {
+		t.Errorf("Divide(start, %d): got %d nodes in total, want %d", depth, total, want)
+	}
+
+	if got := Divide(p, 0); got != nil {
+		t.Errorf("Divide at depth 0: got %v, want nil", got)
+	}
+}
+
+func TestPosition_MoveCountsByPiece(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		want map[PieceType]int
+	}{
+		{"start", StartFEN, map[PieceType]int{Pawn: 16, Knight: 4}},
+		{
+			"castling",
+			"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1",
+			map[PieceType]int{Rook: 19, King: 5 + 2},
+		},
+	}
+
+	for _, test := range tests {
+		p, err := ParseFEN(test.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := p.MoveCountsByPiece(); !maps.Equal(got, test.want) {
+			t.Errorf("%s: MoveCountsByPiece(): got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func BenchmarkPerft(b *testing.B) {
+	p, _ := TestPosition("kiwipete")
+	for b.Loop() {
+		Perft(p, 3)
+	}
+}
@@ -0,0 +1,61 @@
+package core
+
+import "math/bits"
+
+// Offsets into [polyglotRandom].
+const (
+	polyglotCastlingOffset  = 768
+	polyglotEnPassantOffset = 772
+	polyglotTurnOffset      = 780
+)
+
+// PolyglotKey returns the key of p as defined by the Polyglot opening book
+// format, for probing Polyglot books.
+//
+// Use [Position.Hash] instead for hash tables; PolyglotKey is fixed by the
+// book format, while the hash may change. The right to capture en passant only
+// affects the key if a pawn of the player whose turn it is could make the
+// capture.
+func (p *Position) PolyglotKey() uint64 {
+	var key uint64
+	for s := p.Board.Occupied(); s != 0; s &= s - 1 {
+		sq := Square(bits.TrailingZeros64(uint64(s)))
+		piece, _ := p.Board.Piece(sq)
+		key ^= polyglotPieceKey(piece, sq)
+	}
+	return key ^ p.polyglotStateKey()
+}
+
+// polyglotPieceKey returns the part of a Polyglot key for piece on s.
+func polyglotPieceKey(piece Piece, s Square) uint64 {
+	// Polyglot orders pieces as black pawn, white pawn, black knight, white
+	// knight, and so on.
+	kind := 2 * int(piece.PieceType)
+	if piece.Color == White {
+		kind++
+	}
+	return polyglotRandom[64*kind+int(s)]
+}
+
+// polyglotStateKey returns the part of the Polyglot key of p that does not
+// depend on the board: castling rights, en passant, and turn.
+func (p *Position) polyglotStateKey() uint64 {
+	var key uint64
+
+	for i, c := range []Castling{WhiteOO, WhiteOOO, BlackOO, BlackOOO} {
+		if p.Castling.GetAll(c) {
+			key ^= polyglotRandom[polyglotCastlingOffset+i]
+		}
+	}
+
+	ep := p.capturableEnPassant()
+	if s, ok := ep.Square(); ok {
+		key ^= polyglotRandom[polyglotEnPassantOffset+int(s.File())]
+	}
+
+	if p.Turn == White {
+		key ^= polyglotRandom[polyglotTurnOffset]
+	}
+
+	return key
+}
@@ -1,5 +1,7 @@
 package core
 
+import "fmt"
+
 // Position describes a position.
 type Position struct {
 	// TODO(clfs): Make these fields unexported.
@@ -23,20 +25,49 @@ type Position struct {
 	// captures or pawn advances have occurred, this is the number of plies
 	// since the start of the game.
 	FiftyMoveRule uint8
+
+	// The Zobrist hash of the position, kept up to date by Move. See
+	// [Position.Hash].
+	hash Key
 }
 
 // NewPosition returns the starting position.
 func NewPosition() Position {
-	return Position{
+	p := Position{
 		Board:    NewBoard(),
 		Castling: NewCastling(),
 	}
+	p.hash = p.zobrist()
+	return p
+}
+
+// Material returns the material of color c in centipawns, as the sum of the
+// values of its pieces other than the king. It is tracked as moves are made, so
+// it is cheap.
+func (p *Position) Material(c Color) int {
+	return p.Board.material[colorIndex(c)]
+}
+
+// Validate returns an error if p could not arise in a game.
+//
+// It checks that the player whose turn it isn't is not in check, since the
+// move that led to p would then have been illegal.
+func (p *Position) Validate() error {
+	them := p.Turn.Other()
+	if k, ok := p.Board.KingSquare(them); ok && p.Board.IsAttacked(k, p.Turn) {
+		return fmt.Errorf("%v is in check, but it is %v's turn", them, p.Turn)
+	}
+	return nil
 }
 
 // Move makes a move.
 //
 // It does not check for invalid moves.
 func (p *Position) Move(m Move) {
+	// Hash out the board, castling rights, en passant, and turn, which may
+	// all change. The board rehashes its pieces incrementally as they move.
+	p.hash ^= p.Board.key ^ Key(p.polyglotStateKey())
+
 	// Find the move's from and to squares.
 	from, to := m.From(), m.To()
 
@@ -49,8 +80,11 @@ func (p *Position) Move(m Move) {
 	// Is the move an en passant capture?
 	isEnPassantCapture := isPawnMove && p.EnPassant.ExistsAt(to)
 
+	// Is the move a castling move? If so, to holds the castling rook.
+	isCastlingMove := m.IsCastleEncoded()
+
 	// Is the move a regular capture, i.e., not en passant?
-	isRegularCapture := p.Board.IsOccupied(to)
+	isRegularCapture := !isCastlingMove && p.Board.IsOccupied(to)
 
 	// Is the move a capture?
 	isCapture := isRegularCapture || isEnPassantCapture
@@ -79,7 +113,9 @@ func (p *Position) Move(m Move) {
 	}
 
 	// If the held piece leaves a corner square, then the castling right that
-	// involves that square is lost.
+	// involves that square is lost. Castling rights don't record which rook
+	// they belong to, so in Chess960 a rook leaving a square other than a
+	// corner keeps its right until the king moves.
 	switch from {
 	case A1:
 		p.Castling.Clear(WhiteOOO)
@@ -122,30 +158,17 @@ func (p *Position) Move(m Move) {
 		p.EnPassant.Clear()
 	}
 
-	// Is the move a castling move?
-	fromFile, toFile := from.File(), to.File()
-	isCastlingMove := isKingMove && (fromFile == FileE) && (toFile == FileG || toFile == FileC)
-
-	// If the move is a castling move, move the castling rook.
+	// Move the held piece. If castling, lift the rook first, since in Chess960
+	// the king may land on the rook's square or the rook on the king's.
 	if isCastlingMove {
-		var rookFrom, rookTo Square
-		switch {
-		case from == E1 && to == G1: // WhiteOO
-			rookFrom, rookTo = H1, F1
-		case from == E1 && to == C1: // WhiteOOO
-			rookFrom, rookTo = A1, D1
-		case from == E8 && to == G8: // BlackOO
-			rookFrom, rookTo = H8, F8
-		case from == E8 && to == C8: // BlackOOO
-			rookFrom, rookTo = A8, D8
-		}
-		rook := NewPiece(p.Turn, Rook)
-		p.Board.Move(rook, rookFrom, rookTo)
+		kingTo, rookTo := m.castlingSquares()
+		p.Board.Clear(to)
+		p.Board.Move(heldPiece, from, kingTo)
+		p.Board.Set(NewPiece(p.Turn, Rook), rookTo)
+	} else {
+		p.Board.Move(heldPiece, from, to)
 	}
 
-	// Move the held piece. If castling, this is the king.
-	p.Board.Move(heldPiece, from, to)
-
 	// Is the move a promotion?
 	isPromotion := m.IsPromotion()
 
@@ -168,4 +191,89 @@ func (p *Position) Move(m Move) {
 
 	// Finish the turn.
 	p.Turn = p.Turn.Other()
+
+	// Hash in the new board, castling rights, en passant, and turn.
+	p.hash ^= p.Board.key ^ Key(p.polyglotStateKey())
+}
+
+// ParseMove parses a move in UCI long algebraic notation, like "e2e4" or
+// "e7e8q".
+//
+// It returns an error if the from square does not hold a piece belonging to the
+// player whose turn it is. It does not otherwise check for invalid moves.
+//
+// A king moving two squares from its starting square, like "e1g1", or onto a
+// rook of its own color, like "e1h1", is parsed as a castling move.
+func (p *Position) ParseMove(s string) (Move, error) {
+	if len(s) != 4 && len(s) != 5 {
+		return Move{}, fmt.Errorf("invalid move %q", s)
+	}
+
+	from, err := parseSquare(s[0:2])
+	if err != nil {
+		return Move{}, fmt.Errorf("invalid move %q: %w", s, err)
+	}
+	to, err := parseSquare(s[2:4])
+	if err != nil {
+		return Move{}, fmt.Errorf("invalid move %q: %w", s, err)
+	}
+
+	if c, ok := p.Board.PieceColor(from); !ok || c != p.Turn {
+		return Move{}, fmt.Errorf("invalid move %q: no %v piece on %v", s, p.Turn, from)
+	}
+
+	if len(s) == 4 {
+		if pt, _ := p.Board.PieceType(from); pt == King {
+			if rook, ok := p.castlingRook(from, to); ok {
+				return NewCastle(from, rook), nil
+			}
+		}
+		return NewMove(from, to), nil
+	}
+
+	var pt PieceType
+	switch s[4] {
+	case 'n':
+		pt = Knight
+	case 'b':
+		pt = Bishop
+	case 'r':
+		pt = Rook
+	case 'q':
+		pt = Queen
+	default:
+		return Move{}, fmt.Errorf("invalid move %q: invalid promotion %q", s, s[4])
+	}
+	return NewPromotion(from, to, pt), nil
+}
+
+// castlingRook returns the square of the castling rook if a king of the player
+// whose turn it is moving between from and to would be castling: either onto
+// its own rook on its back rank, on any file as in Chess960, while it has the
+// right to castle towards that side, or two squares from E1 or E8 as in
+// standard chess.
+func (p *Position) castlingRook(from, to Square) (Square, bool) {
+	backRank, oo, ooo := Rank1, WhiteOO, WhiteOOO
+	if p.Turn == Black {
+		backRank, oo, ooo = Rank8, BlackOO, BlackOOO
+	}
+	rooks := p.Board.Pieces(NewPiece(p.Turn, Rook))
+	if from.Rank() == backRank && to.Rank() == backRank && rooks.Get(to) {
+		right := ooo
+		if to > from {
+			right = oo
+		}
+		if p.Castling.GetAll(right) {
+			return to, true
+		}
+		return 0, false
+	}
+
+	if !isCastlingShape(p.Turn, from, to) {
+		return 0, false
+	}
+	if to.File() == FileG {
+		return NewSquare(FileH, to.Rank()), true
+	}
+	return NewSquare(FileA, to.Rank()), true
 }
@@ -0,0 +1,212 @@
+package core
+
+import "testing"
+
+func TestPosition_Move(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		move Move
+		want string
+	}{
+		{
+			"double push",
+			StartFEN,
+			NewMove(E2, E4),
+			"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1",
+		},
+		{
+			"capture",
+			"rnbqkbnr/ppp1pppp/8/3p4/4P3/8/PPPP1PPP/RNBQKBNR w KQkq - 0 2",
+			NewMove(E4, D5),
+			"rnbqkbnr/ppp1pppp/8/3P4/8/8/PPPP1PPP/RNBQKBNR b KQkq - 0 2",
+		},
+		{
+			"en passant",
+			"rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3",
+			NewMove(E5, F6),
+			"rnbqkbnr/ppp1p1pp/5P2/3p4/8/8/PPPP1PPP/RNBQKBNR b KQkq - 0 3",
+		},
+		{
+			"castling",
+			"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 3 10",
+			NewCastle(E1, H1),
+			"r3k2r/8/8/8/8/8/8/R4RK1 b kq - 4 10",
+		},
+		{
+			"castling queenside",
+			"r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 3 10",
+			NewCastle(E8, A8),
+			"2kr3r/8/8/8/8/8/8/R3K2R w KQ - 4 11",
+		},
+		{
+			"960 castling onto the rook's square",
+			"4k3/8/8/8/8/8/8/5KR1 w K - 0 1",
+			NewCastle(F1, G1),
+			"4k3/8/8/8/8/8/8/5RK1 b - - 1 1",
+		},
+		{
+			"960 castling without moving the king",
+			"4k3/8/8/8/8/8/8/1RK5 w Q - 0 1",
+			NewCastle(C1, B1),
+			"4k3/8/8/8/8/8/8/2KR4 b - - 1 1",
+		},
+		{
+			"rook move",
+			"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 3 10",
+			NewMove(A1, A8),
+			"R3k2r/8/8/8/8/8/8/4K2R b Kk - 0 10",
+		},
+		{
+			// Capturing a rook on its starting square removes its castling
+			// right, whatever captures it.
+			"rook captures rook on h1",
+			"r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1",
+			NewMove(H8, H1),
+			"r3k3/8/8/8/8/8/8/R3K2r w Qq - 0 2",
+		},
+		{
+			"queen captures rook on a1",
+			"r3k2r/8/8/8/8/8/q7/R3K2R b KQkq - 0 1",
+			NewMove(A2, A1),
+			"r3k2r/8/8/8/8/8/8/q3K2R w Kkq - 0 2",
+		},
+		{
+			"bishop captures rook on a8",
+			"r3k2r/8/8/8/4B3/8/8/R3K2R w KQkq - 0 1",
+			NewMove(E4, A8),
+			"B3k2r/8/8/8/8/8/8/R3K2R b KQk - 0 1",
+		},
+		{
+			"knight captures rook on h8",
+			"r3k2r/8/6N1/8/8/8/8/R3K2R w KQkq - 0 1",
+			NewMove(G6, H8),
+			"r3k2N/8/8/8/8/8/8/R3K2R b KQq - 0 1",
+		},
+		{
+			"promotion",
+			"k7/4P3/8/8/8/8/8/K7 w - - 0 1",
+			NewPromotion(E7, E8, Knight),
+			"k3N3/8/8/8/8/8/8/K7 b - - 0 1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := ParseFEN(test.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p.Move(test.move)
+			if !PositionsEqualFEN(p, test.want) {
+				t.Errorf("Move(%v): got %q, want %q", test.move, p.FEN(), test.want)
+			}
+		})
+	}
+}
+
+// countMaterial returns the material of color c in p, counted from scratch.
+func countMaterial(p *Position, c Color) int {
+	material := 0
+	for pt := Pawn; pt < King; pt++ {
+		b := p.Board.Pieces(NewPiece(c, pt))
+		material += pt.Value() * b.Count()
+	}
+	return material
+}
+
+func TestPosition_Material(t *testing.T) {
+	var walk func(p *Position, depth int, path []Move)
+	walk = func(p *Position, depth int, path []Move) {
+		for _, c := range []Color{White, Black} {
+			if got, want := p.Material(c), countMaterial(p, c); got != want {
+				t.Fatalf("after %v: Material(%v): got %d, want %d", path, c, got, want)
+			}
+		}
+		if depth == 0 {
+			return
+		}
+		for _, m := range p.Moves() {
+			q := *p
+			q.Move(m)
+			walk(&q, depth-1, append(path, m))
+		}
+	}
+
+	fens := []string{
+		StartFEN,
+		testPositions["kiwipete"],
+		// Position 4 from the perft suite has many captures and promotions.
+		"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+	}
+	for _, fen := range fens {
+		p, err := ParseFEN(fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		walk(&p, 3, nil)
+	}
+
+	p := NewPosition()
+	if got, want := p.Material(White), 8*100+2*320+2*330+2*500+900; got != want {
+		t.Errorf("Material(White) at the start: got %d, want %d", got, want)
+	}
+}
+
+func TestPosition_ParseMove(t *testing.T) {
+	const promotion = "4k3/P7/8/8/8/8/8/4K3 w - - 0 1"
+
+	tests := []struct {
+		fen  string
+		s    string
+		want Move
+	}{
+		{StartFEN, "e2e4", NewMove(E2, E4)},
+		{StartFEN, "g1f3", NewMove(G1, F3)},
+		{"4k3/8/8/3p4/4P3/8/8/4K3 w - - 0 1", "e4d5", NewMove(E4, D5)},
+		{promotion, "a7a8q", NewPromotion(A7, A8, Queen)},
+		{promotion, "a7a8r", NewPromotion(A7, A8, Rook)},
+		{promotion, "a7a8b", NewPromotion(A7, A8, Bishop)},
+		{promotion, "a7a8n", NewPromotion(A7, A8, Knight)},
+		{"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "e1g1", NewCastle(E1, H1)},
+	}
+
+	for _, test := range tests {
+		p, err := ParseFEN(test.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := p.ParseMove(test.s)
+		if err != nil {
+			t.Errorf("ParseMove(%q): %v", test.s, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("ParseMove(%q): got %#v, want %#v", test.s, got, test.want)
+		}
+	}
+}
+
+func TestPosition_ParseMove_error(t *testing.T) {
+	p := NewPosition()
+
+	tests := []struct {
+		name string
+		s    string
+	}{
+		{"empty", ""},
+		{"too short", "e2"},
+		{"too long", "e2e4qq"},
+		{"off the board", "z9z9"},
+		{"bad to square", "e2e9"},
+		{"bad promotion", "e2e4k"},
+		{"empty from square", "e4e5"},
+		{"other player's piece", "e7e5"},
+	}
+
+	for _, test := range tests {
+		if m, err := p.ParseMove(test.s); err == nil {
+			t.Errorf("%s: ParseMove(%q): got %v, want error", test.name, test.s, m)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package core
+
+import "fmt"
+
+// Replay plays moves, given in UCI long algebraic notation, from the position
+// described by fen. The fen may also be "startpos", as in the UCI "position"
+// command, for the starting position.
+//
+// It returns the resulting position and the moves played. It returns an error
+// if fen is invalid or at the first invalid or illegal move.
+func Replay(fen string, moves []string) (Position, []Move, error) {
+	start, err := startPosition(fen)
+	if err != nil {
+		return Position{}, nil, err
+	}
+	played := make([]Move, 0, len(moves))
+	p, err := replay(start, moves, func(m Move, _ *Position) {
+		played = append(played, m)
+	})
+	if err != nil {
+		return Position{}, nil, err
+	}
+	return p, played, nil
+}
+
+// ReplayFENs is like [Replay], but it returns the position after each move in
+// Forsyth-Edwards Notation, for stepping through a game.
+func ReplayFENs(fen string, moves []string) ([]string, error) {
+	start, err := startPosition(fen)
+	if err != nil {
+		return nil, err
+	}
+	fens := make([]string, 0, len(moves))
+	_, err = replay(start, moves, func(_ Move, p *Position) {
+		fens = append(fens, p.FEN())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fens, nil
+}
+
+// ReplayGame is like [Replay], but it returns the game, which keeps the
+// position described by fen and every position after it, for detecting
+// repetitions.
+func ReplayGame(fen string, moves []string) (*Game, error) {
+	start, err := startPosition(fen)
+	if err != nil {
+		return nil, err
+	}
+	g := NewGame(start)
+	_, err = replay(start, moves, func(m Move, _ *Position) {
+		g.Move(m)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// startPosition returns the position described by fen, which may also be
+// "startpos" for the starting position.
+func startPosition(fen string) (Position, error) {
+	if fen == "startpos" {
+		return NewPosition(), nil
+	}
+	return ParseFEN(fen)
+}
+
+// replay implements [Replay]. It plays moves from p and calls played after
+// each move with the move and the resulting position.
+func replay(p Position, moves []string, played func(Move, *Position)) (Position, error) {
+	for i, s := range moves {
+		m, err := p.ParseMove(s)
+		if err != nil {
+			return Position{}, fmt.Errorf("move %d: %w", i+1, err)
+		}
+		if err := p.MoveError(m); err != nil {
+			return Position{}, fmt.Errorf("move %d: %w", i+1, err)
+		}
+		p.Move(m)
+		played(m, &p)
+	}
+
+	return p, nil
+}
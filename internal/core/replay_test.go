@@ -0,0 +1,124 @@
+package core
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReplay(t *testing.T) {
+	// The opening of the Opera Game, Morphy vs. the Duke and Count, 1858.
+	moves := strings.Fields("e2e4 e7e5 g1f3 d7d6 d2d4 c8g4 d4e5 g4f3 d1f3 d6e5 f1c4 g8f6 f3b3 d8e7")
+	const want = "rn2kb1r/ppp1qppp/5n2/4p3/2B1P3/1Q6/PPP2PPP/RNB1K2R w KQkq - 4 8"
+
+	p, played, err := Replay("startpos", moves)
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if got := p.FEN(); got != want {
+		t.Errorf("Replay: got %q, want %q", got, want)
+	}
+	if len(played) != len(moves) {
+		t.Fatalf("Replay: got %d moves played, want %d", len(played), len(moves))
+	}
+	for i, m := range played {
+		if m.String() != moves[i] {
+			t.Errorf("Replay: got move %d %v, want %s", i+1, m, moves[i])
+		}
+	}
+}
+
+func TestReplay_fromFEN(t *testing.T) {
+	p, _, err := Replay("6k1/5ppp/8/8/8/8/5PPP/3R2K1 b - - 0 1", []string{"g8f8", "g1f1", "f8g8"})
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if got, want := p.FEN(), "6k1/5ppp/8/8/8/8/5PPP/3R1K2 w - - 3 3"; got != want {
+		t.Errorf("Replay: got %q, want %q", got, want)
+	}
+}
+
+func TestReplayFENs(t *testing.T) {
+	moves := strings.Fields("e2e4 e7e5 g1f3")
+	want := []string{
+		"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1",
+		"rnbqkbnr/pppp1ppp/8/4p3/4P3/8/PPPP1PPP/RNBQKBNR w KQkq e6 0 2",
+		"rnbqkbnr/pppp1ppp/8/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq - 1 2",
+	}
+
+	got, err := ReplayFENs("startpos", moves)
+	if err != nil {
+		t.Fatalf("ReplayFENs: %v", err)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("ReplayFENs: got %q, want %q", got, want)
+	}
+
+	// The last FEN is the final position.
+	p, _, err := Replay("startpos", moves)
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if last := got[len(got)-1]; last != p.FEN() {
+		t.Errorf("ReplayFENs: got last FEN %q, want %q", last, p.FEN())
+	}
+
+	if _, err := ReplayFENs("startpos", []string{"e2e4", "e2e4"}); err == nil {
+		t.Error("ReplayFENs with an illegal move: got nil error")
+	}
+}
+
+func TestReplayGame(t *testing.T) {
+	moves := strings.Fields("g1f3 g8f6 f3g1 f6g8")
+	g, err := ReplayGame("startpos", moves)
+	if err != nil {
+		t.Fatalf("ReplayGame: %v", err)
+	}
+	if got, err := g.FENAt(0); err != nil || got != StartFEN {
+		t.Errorf("ReplayGame: got start %q, %v, want %q", got, err, StartFEN)
+	}
+	p, _, err := Replay("startpos", moves)
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if got := g.Position(); got != p {
+		t.Errorf("ReplayGame: got position %q, want %q", got.FEN(), p.FEN())
+	}
+	if got := g.Repetitions(); got != 2 {
+		t.Errorf("ReplayGame: got %d repetitions, want 2", got)
+	}
+
+	for _, test := range []struct{ fen, moves string }{
+		{"not a fen", ""},
+		{"startpos", "e2e4 e2e4"},
+	} {
+		if _, err := ReplayGame(test.fen, strings.Fields(test.moves)); err == nil {
+			t.Errorf("ReplayGame(%q, %q): got nil error", test.fen, test.moves)
+		}
+	}
+}
+
+func TestReplay_error(t *testing.T) {
+	tests := []struct {
+		name  string
+		fen   string
+		moves string
+	}{
+		{"invalid FEN", "not a fen", ""},
+		{"invalid move", "startpos", "e2e4 hello"},
+		{"illegal move", "startpos", "e2e4 e7e5 e1e3"},
+	}
+
+	for _, test := range tests {
+		if _, _, err := Replay(test.fen, strings.Fields(test.moves)); err == nil {
+			t.Errorf("%s: got nil error", test.name)
+		}
+	}
+
+	// Illegal moves are reported as a *MoveError.
+	_, _, err := Replay("startpos", []string{"e2e4", "e7e5", "e1e3"})
+	if e := new(MoveError); !errors.As(err, &e) || e.Reason != InvalidMovement {
+		t.Errorf("illegal move: got %v, want a *MoveError for invalid movement", err)
+	}
+}
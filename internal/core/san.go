@@ -0,0 +1,195 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var regexpSAN = regexp.MustCompile(`^([NBRQK])?([a-h])?([1-8])?x?([a-h][1-8])(?:=?([NBRQ]))?$`)
+
+// ParseSAN parses a legal move in Standard Algebraic Notation, like "Nf3",
+// "exd5", or "e8=Q".
+//
+// Castling may be written with letters ("O-O", "O-O-O") or digits ("0-0",
+// "0-0-0"). Check and checkmate indicators and move annotations like "!?" are
+// ignored.
+func (p *Position) ParseSAN(s string) (Move, error) {
+	san := strings.TrimRight(s, "+#!?")
+
+	var match func(m Move, pt PieceType) bool
+
+	switch san {
+	case "O-O", "0-0":
+		match = func(m Move, pt PieceType) bool {
+			return m.IsCastleEncoded() && m.From() < m.To()
+		}
+	case "O-O-O", "0-0-0":
+		match = func(m Move, pt PieceType) bool {
+			return m.IsCastleEncoded() && m.From() > m.To()
+		}
+	default:
+		subs := regexpSAN.FindStringSubmatch(san)
+		if subs == nil {
+			return Move{}, fmt.Errorf("invalid SAN move %q", s)
+		}
+
+		piece := Pawn
+		if subs[1] != "" {
+			piece, _ = pieceTypeFromLetter(subs[1][0])
+		}
+		to, _ := parseSquare(subs[4])
+		var promotion PieceType
+		if subs[5] != "" {
+			promotion, _ = pieceTypeFromLetter(subs[5][0])
+		}
+
+		match = func(m Move, pt PieceType) bool {
+			from := m.From()
+			switch {
+			case pt != piece, m.To() != to, m.promotion != promotion:
+				return false
+			case subs[2] != "" && from.File() != File(subs[2][0]-'a'):
+				return false
+			case subs[3] != "" && from.Rank() != Rank(subs[3][0]-'1'):
+				return false
+			case m.IsCastleEncoded():
+				// Castling must be written as such.
+				return false
+			}
+			return true
+		}
+	}
+
+	var found []Move
+	for _, m := range p.Moves() {
+		pt, _ := p.Board.PieceType(m.From())
+		if match(m, pt) {
+			found = append(found, m)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return Move{}, fmt.Errorf("illegal SAN move %q", s)
+	case 1:
+		return found[0], nil
+	default:
+		return Move{}, fmt.Errorf("ambiguous SAN move %q", s)
+	}
+}
+
+// SAN returns m, a legal move, in Standard Algebraic Notation, like "Nf3",
+// "exd5", or "e8=Q+".
+//
+// Moves are disambiguated by file, rank, or both, as needed. A "+" or "#" is
+// appended if the move gives check or checkmate.
+func (p *Position) SAN(m Move) string {
+	return string(p.appendSAN(nil, m, p.Moves()))
+}
+
+// LegalSAN returns all legal moves in Standard Algebraic Notation, in the order
+// [Position.Moves] returns them.
+func (p *Position) LegalSAN() []string {
+	moves := p.Moves()
+	sans := make([]string, len(moves))
+	for i, m := range moves {
+		sans[i] = string(p.appendSAN(nil, m, moves))
+	}
+	return sans
+}
+
+// appendSAN appends m in Standard Algebraic Notation to b. The legal moves of p
+// are given in legal, for disambiguation.
+func (p *Position) appendSAN(b []byte, m Move, legal []Move) []byte {
+	from, to := m.From(), m.To()
+	pt, _ := p.Board.PieceType(from)
+
+	switch {
+	case m.IsCastleEncoded() && from < to:
+		b = append(b, "O-O"...)
+	case m.IsCastleEncoded():
+		b = append(b, "O-O-O"...)
+	case pt == Pawn:
+		// Pawns change files only when capturing, possibly en passant.
+		if from.File() != to.File() {
+			b = append(b, 'a'+byte(from.File()), 'x')
+		}
+		b = appendSquare(b, to)
+		if promotion, ok := m.PromotionTo(); ok {
+			b = append(b, '=', NewPiece(White, promotion).Letter())
+		}
+	default:
+		b = append(b, NewPiece(White, pt).Letter())
+		b = p.appendDisambiguation(b, m, pt, legal)
+		if p.Board.IsOccupied(to) {
+			b = append(b, 'x')
+		}
+		b = appendSquare(b, to)
+	}
+
+	q := *p
+	q.Move(m)
+	if q.InCheck() {
+		if len(q.Moves()) == 0 {
+			b = append(b, '#')
+		} else {
+			b = append(b, '+')
+		}
+	}
+
+	return b
+}
+
+// appendDisambiguation appends the file, rank, or square of the from square of
+// m, as needed to tell it apart from other legal moves by pieces of type pt to
+// the same square.
+func (p *Position) appendDisambiguation(b []byte, m Move, pt PieceType, legal []Move) []byte {
+	var ambiguous, sameFile, sameRank bool
+	for _, other := range legal {
+		if other.To() != m.To() || other.From() == m.From() {
+			continue
+		}
+		if opt, _ := p.Board.PieceType(other.From()); opt != pt {
+			continue
+		}
+		ambiguous = true
+		sameFile = sameFile || other.From().File() == m.From().File()
+		sameRank = sameRank || other.From().Rank() == m.From().Rank()
+	}
+
+	switch {
+	case !ambiguous:
+		return b
+	case !sameFile:
+		return append(b, 'a'+byte(m.From().File()))
+	case !sameRank:
+		return append(b, '1'+byte(m.From().Rank()))
+	default:
+		return appendSquare(b, m.From())
+	}
+}
+
+// ParseMoveLenient parses a legal move in either UCI long algebraic notation,
+// like "g1f3", or Standard Algebraic Notation, like "Nf3".
+//
+// Unlike [Position.ParseMove], it returns an error for illegal moves.
+func (p *Position) ParseMoveLenient(s string) (Move, error) {
+	if m, err := p.ParseMove(s); err == nil {
+		if !p.IsLegal(m) {
+			return Move{}, p.MoveError(m)
+		}
+		return m, nil
+	}
+	return p.ParseSAN(s)
+}
+
+// pieceTypeFromLetter returns the piece type for an uppercase letter, like 'N'
+// for [Knight].
+func pieceTypeFromLetter(c byte) (PieceType, bool) {
+	i := strings.IndexByte("PNBRQK", c)
+	if i < 0 {
+		return 0, false
+	}
+	return PieceType(i), true
+}
@@ -0,0 +1,161 @@
+package core
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPosition_ParseSAN(t *testing.T) {
+	// Both players can castle either way.
+	const castling = "r3k2r/pppppppp/8/8/8/8/PPPPPPPP/R3K2R w KQkq - 0 1"
+
+	tests := []struct {
+		name string
+		fen  string
+		san  string
+		want Move
+	}{
+		{"pawn push", StartFEN, "e4", NewMove(E2, E4)},
+		{"knight move", StartFEN, "Nf3", NewMove(G1, F3)},
+		{"check indicator", StartFEN, "Nc3+", NewMove(B1, C3)},
+		{"annotation", StartFEN, "e4!?", NewMove(E2, E4)},
+		{"pawn capture", "4k3/8/8/3p4/4P3/8/8/4K3 w - - 0 1", "exd5", NewMove(E4, D5)},
+		{"file disambiguation", "4k3/8/8/8/8/8/4K3/R6R w - - 0 1", "Rhd1", NewMove(H1, D1)},
+		{"rank disambiguation", "4k3/R7/8/8/8/8/R7/4K3 w - - 0 1", "R7a5", NewMove(A7, A5)},
+		{"promotion", "4k3/P7/8/8/8/8/8/4K3 w - - 0 1", "a8=Q", NewPromotion(A7, A8, Queen)},
+		{"promotion without equals", "4k3/P7/8/8/8/8/8/4K3 w - - 0 1", "a8N", NewPromotion(A7, A8, Knight)},
+		{"castle kingside with letters", castling, "O-O", NewCastle(E1, H1)},
+		{"castle kingside with digits", castling, "0-0", NewCastle(E1, H1)},
+		{"castle queenside with letters", castling, "O-O-O", NewCastle(E1, A1)},
+		{"castle queenside with digits", castling, "0-0-0", NewCastle(E1, A1)},
+		{"black castles with check indicator", "r3k2r/pppppppp/8/8/8/8/PPPPPPPP/R3K2R b KQkq - 0 1", "0-0+", NewCastle(E8, H8)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := ParseFEN(test.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := p.ParseSAN(test.san)
+			if err != nil {
+				t.Fatalf("ParseSAN(%q): %v", test.san, err)
+			}
+			if got != test.want {
+				t.Errorf("ParseSAN(%q): got %v, want %v", test.san, got, test.want)
+			}
+		})
+	}
+}
+
+func TestPosition_ParseSAN_error(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		san  string
+	}{
+		{"empty", StartFEN, ""},
+		{"garbage", StartFEN, "hello"},
+		{"illegal", StartFEN, "e5"},
+		{"castling blocked", StartFEN, "O-O"},
+		{"ambiguous", "4k3/8/8/8/8/8/4K3/R6R w - - 0 1", "Rd1"},
+		{"castling as king move", "4k3/8/8/8/8/8/8/4K2R w K - 0 1", "Kg1"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := ParseFEN(test.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m, err := p.ParseSAN(test.san); err == nil {
+				t.Errorf("ParseSAN(%q): got %v, want error", test.san, m)
+			}
+		})
+	}
+}
+
+func TestPosition_ParseMoveLenient(t *testing.T) {
+	p := NewPosition()
+
+	tests := []struct {
+		s    string
+		want Move
+	}{
+		{"g1f3", NewMove(G1, F3)},
+		{"Nf3", NewMove(G1, F3)},
+		{"e4", NewMove(E2, E4)},
+		{"e2e4", NewMove(E2, E4)},
+	}
+	for _, test := range tests {
+		got, err := p.ParseMoveLenient(test.s)
+		if err != nil {
+			t.Errorf("ParseMoveLenient(%q): %v", test.s, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("ParseMoveLenient(%q): got %v, want %v", test.s, got, test.want)
+		}
+	}
+
+	for _, s := range []string{"", "g1g3", "Nf4", "e5"} {
+		if _, err := p.ParseMoveLenient(s); err == nil {
+			t.Errorf("ParseMoveLenient(%q): got nil error", s)
+		}
+	}
+}
+
+func TestPosition_SAN(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		m    Move
+		want string
+	}{
+		{"pawn push", StartFEN, NewMove(E2, E4), "e4"},
+		{"knight move", StartFEN, NewMove(G1, F3), "Nf3"},
+		{"pawn capture", "4k3/8/8/3p4/4P3/8/8/4K3 w - - 0 1", NewMove(E4, D5), "exd5"},
+		{"en passant", "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1", NewMove(E5, D6), "exd6"},
+		{"piece capture", "4k3/8/8/3p4/8/4N3/8/4K3 w - - 0 1", NewMove(E3, D5), "Nxd5"},
+		{"file disambiguation", "4k3/8/8/8/8/8/4K3/R6R w - - 0 1", NewMove(H1, D1), "Rhd1"},
+		{"rank disambiguation", "4k3/R7/8/8/8/8/R7/4K3 w - - 0 1", NewMove(A7, A5), "R7a5"},
+		{"square disambiguation", "8/7k/8/8/Q5Q1/8/8/Q3K3 w - - 0 1", NewMove(A4, D1), "Qa4d1"},
+		{"promotion", "8/P7/8/8/8/8/8/4K1k1 w - - 0 1", NewPromotion(A7, A8, Queen), "a8=Q"},
+		{"promotion with check", "4k3/P7/8/8/8/8/8/4K3 w - - 0 1", NewPromotion(A7, A8, Queen), "a8=Q+"},
+		{"castle kingside", "r3k2r/pppppppp/8/8/8/8/PPPPPPPP/R3K2R w KQkq - 0 1", NewCastle(E1, H1), "O-O"},
+		{"castle queenside", "r3k2r/pppppppp/8/8/8/8/PPPPPPPP/R3K2R b KQkq - 0 1", NewCastle(E8, A8), "O-O-O"},
+		{"checkmate", "6k1/5ppp/8/8/8/8/5PPP/3R2K1 w - - 0 1", NewMove(D1, D8), "Rd8#"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := ParseFEN(test.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := p.SAN(test.m)
+			if got != test.want {
+				t.Errorf("SAN(%v): got %q, want %q", test.m, got, test.want)
+			}
+			// SAN round-trips through ParseSAN.
+			if m, err := p.ParseSAN(got); err != nil || m != test.m {
+				t.Errorf("ParseSAN(%q): got %v, %v, want %v", got, m, err, test.m)
+			}
+		})
+	}
+}
+
+func TestPosition_LegalSAN(t *testing.T) {
+	p := NewPosition()
+	got := p.LegalSAN()
+	slices.Sort(got)
+
+	want := []string{
+		"Na3", "Nc3", "Nf3", "Nh3",
+		"a3", "a4", "b3", "b4", "c3", "c4", "d3", "d4",
+		"e3", "e4", "f3", "f4", "g3", "g4", "h3", "h4",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("LegalSAN(): got %q, want %q", got, want)
+	}
+}
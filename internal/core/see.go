@@ -0,0 +1,66 @@
+package core
+
+// SEE returns the static exchange evaluation of m in centipawns: the material
+// the player to move wins or loses if both players keep capturing on the
+// target square of m with their least valuable piece, stopping whenever that
+// is better for them. Piece values come from [PieceType.Value], whose sentinel
+// value for kings keeps them from recapturing onto defended squares.
+//
+// SEE does not consider pins or checks, or promotions other than by m itself.
+// Castling moves score 0.
+func (p *Position) SEE(m Move) int {
+	if m.IsCastleEncoded() {
+		return 0
+	}
+	from, to := m.From(), m.To()
+	attacker, ok := p.Board.PieceType(from)
+	if !ok {
+		return 0
+	}
+	occupied := p.Board.Occupied() &^ from.Bitboard()
+
+	// gain[d] is the material won by the player making the capture at depth d,
+	// if the other player doesn't recapture. There are at most 32 captures.
+	var gain [32]int
+	if captured, ok := p.Board.PieceType(to); ok {
+		gain[0] = captured.Value()
+	} else if attacker == Pawn && p.EnPassant.ExistsAt(to) {
+		gain[0] = Pawn.Value()
+		occupied &^= NewSquare(to.File(), from.Rank()).Bitboard()
+	}
+	if promo, ok := m.PromotionTo(); ok {
+		gain[0] += promo.Value() - Pawn.Value()
+		attacker = promo
+	}
+
+	d := 0
+	for side := p.Turn.Other(); d < len(gain)-1; side = side.Other() {
+		attackers := p.Board.attackersTo(to, occupied) & occupied & p.Board.color(side)
+		s, pt, ok := p.Board.leastValuable(attackers)
+		if !ok {
+			break
+		}
+		d++
+		gain[d] = attacker.Value() - gain[d-1]
+		attacker = pt
+		occupied &^= s.Bitboard()
+	}
+
+	// Each player may stop capturing instead.
+	for ; d > 0; d-- {
+		gain[d-1] = -max(-gain[d-1], gain[d])
+	}
+	return gain[0]
+}
+
+// leastValuable returns the square and type of the least valuable piece in
+// pieces, or false if pieces is empty.
+func (b *Board) leastValuable(pieces Bitboard) (Square, PieceType, bool) {
+	for pt := Pawn; pt <= King; pt++ {
+		of := pieces & b.pieces[pt]
+		if s, ok := of.Next(); ok {
+			return s, pt, true
+		}
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,106 @@
+package core
+
+import "testing"
+
+func TestPosition_SEE(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		move string
+		want int
+	}{
+		{
+			name: "pawn takes undefended knight",
+			fen:  "4k3/8/8/3n4/4P3/8/8/4K3 w - - 0 1",
+			move: "e4d5",
+			want: Knight.Value(),
+		},
+		{
+			name: "rook takes pawn defended by pawn",
+			fen:  "4k3/2p5/3p4/8/8/8/3R4/4K3 w - - 0 1",
+			move: "d2d6",
+			want: Pawn.Value() - Rook.Value(),
+		},
+		{
+			name: "knight takes pawn defended by rook, backed by rook",
+			fen:  "3rk3/8/8/3p4/8/2N5/8/3RK3 w - - 0 1",
+			move: "c3d5",
+			want: Pawn.Value(),
+		},
+		{
+			name: "queen takes defended pawn and is lost",
+			fen:  "4k3/8/2b5/3p4/8/8/8/3QK3 w - - 0 1",
+			move: "d1d5",
+			want: Pawn.Value() - Queen.Value(),
+		},
+		{
+			name: "rook x-ray behind queen",
+			fen:  "4k3/3r4/8/3p4/8/8/3Q4/3RK3 w - - 0 1",
+			move: "d2d5",
+			want: Pawn.Value() - Queen.Value() + Rook.Value(),
+		},
+		{
+			name: "king can't recapture a defended square",
+			fen:  "8/8/8/3pk3/8/8/3R4/3RK3 w - - 0 1",
+			move: "d2d5",
+			want: Pawn.Value(),
+		},
+		{
+			name: "king takes undefended pawn",
+			fen:  "4k3/8/8/8/8/8/3p4/4K3 w - - 0 1",
+			move: "e1d2",
+			want: Pawn.Value(),
+		},
+		{
+			name: "quiet move to an attacked square",
+			fen:  "4k3/8/8/3p4/8/8/8/1N2K3 w - - 0 1",
+			move: "b1c3",
+			want: 0,
+		},
+		{
+			name: "en passant",
+			fen:  "4k3/8/8/3Pp3/8/8/8/4K3 w - e6 0 1",
+			move: "d5e6",
+			want: Pawn.Value(),
+		},
+		{
+			name: "promotion",
+			fen:  "4k3/1P6/8/8/8/8/8/4K3 w - - 0 1",
+			move: "b7b8q",
+			want: Queen.Value() - Pawn.Value(),
+		},
+		{
+			name: "castling",
+			fen:  "4k3/8/8/8/8/8/8/4K2R w K - 0 1",
+			move: "e1g1",
+			want: 0,
+		},
+	}
+
+	for _, test := range tests {
+		p, err := ParseFEN(test.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, err := p.ParseMove(test.move)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := p.SEE(m); got != test.want {
+			t.Errorf("%s: SEE(%v): got %d, want %d", test.name, m, got, test.want)
+		}
+	}
+}
+
+func TestPieceType_Value(t *testing.T) {
+	// The king's sentinel value outweighs every other piece a player could
+	// have, and material counts leave it out.
+	most := 8*Queen.Value() + 2*(Rook.Value()+Bishop.Value()+Knight.Value()) + Queen.Value()
+	if King.Value() <= most {
+		t.Errorf("King.Value(): got %d, want more than %d", King.Value(), most)
+	}
+	p := NewPosition()
+	if got, want := p.Material(White), 8*Pawn.Value()+2*(Knight.Value()+Bishop.Value()+Rook.Value())+Queen.Value(); got != want {
+		t.Errorf("Material(White): got %d, want %d", got, want)
+	}
+}
@@ -0,0 +1,35 @@
+package core
+
+// testPositions holds the FENs of well-known positions, by name.
+var testPositions = map[string]string{
+	"start": StartFEN,
+
+	// A position rich in tricky moves, from the Chess Programming Wiki.
+	"kiwipete": "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+
+	// Capturing en passant would expose the white king to the rook.
+	"enpassant-pin": "8/8/8/K1Pp3r/8/8/8/k7 w - d6 0 1",
+
+	// Black has no legal moves but isn't in check.
+	"stalemate": "7k/5Q2/6K1/8/8/8/8/8 b - - 0 1",
+
+	// White mates in two, for example with 1. Ra7 Kg8 2. Rb8#.
+	"mate-in-2": "7k/8/8/8/8/8/R7/1R4K1 w - - 0 1",
+}
+
+// TestPosition returns the well-known position with the given name and true,
+// or false if there is none. It's meant for tests.
+//
+// The names are "start", "kiwipete", "enpassant-pin", "stalemate", and
+// "mate-in-2".
+func TestPosition(name string) (Position, bool) {
+	fen, ok := testPositions[name]
+	if !ok {
+		return Position{}, false
+	}
+	p, err := ParseFEN(fen)
+	if err != nil {
+		panic(err)
+	}
+	return p, true
+}
@@ -0,0 +1,27 @@
+package core
+
+import "testing"
+
+func TestTestPosition(t *testing.T) {
+	for name, fen := range testPositions {
+		if _, err := ParseFEN(fen); err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		p, ok := TestPosition(name)
+		if !ok {
+			t.Errorf("TestPosition(%q): got false, want true", name)
+		}
+		if got := p.FEN(); got != fen {
+			t.Errorf("TestPosition(%q): got %q, want %q", name, got, fen)
+		}
+	}
+
+	if _, ok := TestPosition("no such position"); ok {
+		t.Errorf("TestPosition(%q): got true, want false", "no such position")
+	}
+
+	if p, _ := TestPosition("stalemate"); len(p.Moves()) != 0 || p.InCheck() {
+		t.Errorf("TestPosition(%q): got a position that isn't stalemate", "stalemate")
+	}
+}
@@ -1,4 +1,425 @@
 // Package engine implements a chess engine.
 package engine
 
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/clfs/they/internal/core"
+	"github.com/clfs/they/internal/search"
+	"github.com/clfs/they/internal/uci"
+)
+
+// Banner is the line the engine writes on startup if [Options.Banner] is set.
 const Banner = "they!"
+
+// ttSize is the number of entries in the transposition table.
+const ttSize = 1 << 18
+
+// Engine identification, sent in response to a "uci" command.
+const (
+	name   = "they"
+	author = "clfs"
+)
+
+// Engine is a UCI chess engine.
+//
+// Searches run in the background, so the engine keeps responding to commands
+// like "isready" and "stop" while it thinks.
+type Engine struct {
+	opts Options
+
+	// The game so far. The engine searches from its current position.
+	game *core.Game
+
+	// The transposition table, shared by searches until a new game starts.
+	tt *search.TT
+
+	// Stops the running search, if any.
+	cancel context.CancelFunc
+
+	// Receives the result of writing the best move once the running search
+	// finishes. It is nil if no search is running.
+	done chan error
+}
+
+// Options configure an [Engine].
+//
+// The zero value for each field indicates it is not set.
+type Options struct {
+	// The depth to search to when a "go" command doesn't give one. It is
+	// ignored for "go infinite".
+	DefaultDepth int
+
+	// The least depth to search to, overriding a smaller depth in a "go"
+	// command. A time limit can still stop the search earlier.
+	MinDepth int
+
+	// The greatest depth to search to, overriding a larger depth in a "go"
+	// command.
+	MaxDepth int
+
+	// Whether to write [Banner] before reading any commands. It's friendly
+	// for people, but GUIs expect nothing but UCI messages.
+	Banner bool
+}
+
+// depth returns the depth to search to for msg, given the depth requested by
+// msg or the maximum search depth if it requests none.
+func (o *Options) depth(depth int, msg *uci.Go) int {
+	if msg.Depth <= 0 && !msg.Infinite && o.DefaultDepth > 0 {
+		depth = o.DefaultDepth
+	}
+	if o.MaxDepth > 0 {
+		depth = min(depth, o.MaxDepth)
+	}
+	if o.MinDepth > 0 {
+		depth = max(depth, o.MinDepth)
+	}
+	return min(depth, search.MaxDepth)
+}
+
+// New returns a new [Engine] set to the starting position, with default
+// options.
+func New() *Engine {
+	return NewWithOptions(Options{})
+}
+
+// NewWithOptions returns a new [Engine] set to the starting position, with the
+// given options.
+func NewWithOptions(opts Options) *Engine {
+	return &Engine{
+		opts: opts,
+		game: core.NewGame(core.NewPosition()),
+		tt:   search.NewTT(ttSize),
+	}
+}
+
+// Run reads commands from r and writes responses to w until it reads a "quit"
+// command or reaches the end of r.
+//
+// Before returning, Run stops any running search.
+func (e *Engine) Run(r io.Reader, w io.Writer) error {
+	if e.opts.Banner {
+		if _, err := io.WriteString(w, Banner+"\n"); err != nil {
+			return err
+		}
+	}
+
+	dec := uci.NewDecoder(r)
+	dec.SetDirection(uci.EngineInput)
+	enc := uci.NewEncoder(w)
+
+	for {
+		msg, err := dec.ReadMessage()
+		if errors.Is(err, io.EOF) {
+			return e.stopSearch()
+		}
+		if err != nil {
+			return errors.Join(err, e.stopSearch())
+		}
+
+		switch msg := msg.(type) {
+		case *uci.Blank, *uci.Unknown, *uci.Register:
+			// Per the UCI protocol, skip anything that isn't a command. The
+			// engine needs no registration, so skip that too.
+		case *uci.UCI:
+			err = e.handleUCI(enc)
+		case *uci.IsReady:
+			err = enc.WriteMessage(&uci.ReadyOk{})
+		case *uci.SetOption:
+			e.handleSetOption(msg)
+		case *uci.UCINewGame:
+			err = e.handleUCINewGame()
+		case *uci.Position:
+			// A bad position is the GUI's mistake, so report it and carry on.
+			if perr := e.handlePosition(msg); perr != nil {
+				err = enc.WriteMessage(uci.NewInfo(uci.WithString(perr.Error())))
+			}
+		case *uci.Go:
+			err = e.handleGo(enc, msg)
+		case *uci.Stop:
+			err = e.stopSearch()
+		case *uci.Quit:
+			return e.stopSearch()
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// handleUCI identifies the engine.
+func (e *Engine) handleUCI(enc *uci.Encoder) error {
+	msgs := []uci.Message{
+		&uci.ID{Name: name},
+		&uci.ID{Author: author},
+		&uci.UCIOk{},
+	}
+	for _, msg := range msgs {
+		if err := enc.WriteMessage(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handleSetOption sets an option. Options may be set at any time, even before
+// the "uci" command.
+//
+// The engine has no options yet. Per the UCI protocol, options it doesn't
+// recognize are ignored.
+func (e *Engine) handleSetOption(msg *uci.SetOption) {}
+
+// handleUCINewGame resets the engine for a new game.
+func (e *Engine) handleUCINewGame() error {
+	// The transposition table must not be cleared during a search.
+	if err := e.stopSearch(); err != nil {
+		return err
+	}
+	e.game = core.NewGame(core.NewPosition())
+	e.tt.Clear()
+	return nil
+}
+
+// handlePosition sets up the position described by msg.
+//
+// If msg has an invalid FEN or an invalid or illegal move, handlePosition
+// returns an error and leaves the engine's position unchanged.
+func (e *Engine) handlePosition(msg *uci.Position) error {
+	fen := msg.FEN
+	if msg.Startpos {
+		fen = "startpos"
+	}
+
+	// Keep the earlier positions, to detect repetitions.
+	g, err := core.ReplayGame(fen, msg.Moves)
+	if err != nil {
+		return err
+	}
+	e.game = g
+	return nil
+}
+
+// handleGo starts searching the current position in the background. When the
+// search finishes, it reports the best move.
+//
+// If a search is already running, handleGo stops it first.
+func (e *Engine) handleGo(enc *uci.Encoder, msg *uci.Go) error {
+	if err := e.stopSearch(); err != nil {
+		return err
+	}
+
+	if msg.Perft > 0 {
+		return e.handlePerft(enc, msg.Perft)
+	}
+
+	p := e.game.Position()
+
+	// The UCI protocol uses "0000" for a null move, which is sent when the
+	// game is already over.
+	if e.game.IsOver() {
+		return writeResult(enc, gameOverInfo(&p), "0000")
+	}
+
+	depth, budget := searchLimits(msg, p.Turn)
+	depth = e.opts.depth(depth, msg)
+	if _, ok := p.IsForced(); ok && !msg.Infinite {
+		// There's nothing to think about, so answer right away.
+		depth = 1
+	}
+	searchMoves := e.searchMoves(msg)
+	history := e.game.HistoryKeys()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+	e.done = make(chan error, 1)
+
+	go func(p core.Position, done chan<- error) {
+		// Report each completed iteration, so that long searches show
+		// progress. Only the first write error is kept.
+		var progressErr error
+		progress := func(it search.Iteration) {
+			if progressErr == nil {
+				progressErr = enc.WriteMessage(e.iterationInfo(&p, it))
+			}
+		}
+
+		best, score, stats := search.Search(ctx, p, search.Options{
+			Depth:       depth,
+			SearchMoves: searchMoves,
+			History:     history,
+			TT:          e.tt,
+			Budget:      budget,
+			Progress:    progress,
+		})
+		if progressErr != nil {
+			done <- progressErr
+			return
+		}
+
+		// The UCI protocol uses "0000" for a null move, which is sent when
+		// there are no legal moves.
+		if best == (core.Move{}) {
+			done <- writeResult(enc, gameOverInfo(&p), "0000")
+			return
+		}
+
+		info := e.iterationInfo(&p, search.Iteration{Move: best, Score: score, Stats: stats})
+		done <- writeResult(enc, info, best.String())
+	}(p, e.done)
+
+	return nil
+}
+
+// iterationInfo returns the info line reporting it, an iteration of a search
+// of p, including the principal variation and how full the transposition table
+// is.
+func (e *Engine) iterationInfo(p *core.Position, it search.Iteration) *searchInfo {
+	return &searchInfo{
+		Info: uci.Info{
+			Depth:    it.Stats.Depth,
+			SelDepth: it.Stats.SelDepth,
+			Score:    uciScore(it.Score),
+			Nodes:    it.Stats.Nodes,
+			NPS:      nps(it.Stats),
+			Time:     it.Stats.Time,
+			HashFull: e.tt.HashFull(),
+		},
+		pv: e.tt.PV(*p, it.Move, it.Stats.Depth),
+	}
+}
+
+// searchInfo is an info line about a search. Its principal variation is kept
+// as moves and formatted straight into the encoder's buffer, rather than as a
+// string per move.
+type searchInfo struct {
+	uci.Info
+	pv []core.Move
+}
+
+// AppendText implements [encoding.TextAppender].
+func (m *searchInfo) AppendText(b []byte) ([]byte, error) {
+	b, err := m.Info.AppendText(b)
+	if err != nil {
+		return nil, err
+	}
+	if len(m.pv) > 0 {
+		b = append(b, " pv "...)
+		b = core.AppendMoveList(b, m.pv)
+	}
+	return b, nil
+}
+
+// writeResult reports the result of a "go" command: a final info line with
+// the search summary, then the best move.
+func writeResult(enc *uci.Encoder, info uci.Message, best string) error {
+	if err := enc.WriteMessage(info); err != nil {
+		return err
+	}
+	return enc.WriteMessage(&uci.BestMove{Move: best})
+}
+
+// gameOverInfo returns the info line to report for p when the game is over.
+func gameOverInfo(p *core.Position) *uci.Info {
+	if p.IsCheckmate() {
+		return uci.NewInfo(uci.WithString("checkmate"))
+	}
+	return uci.NewInfo(uci.WithScoreCP(0), uci.WithString("draw"))
+}
+
+// handlePerft counts the leaf nodes below each legal move of the current
+// position to the given depth, and reports the counts and their total.
+//
+// Unlike a search, it runs to completion before the engine reads another
+// command.
+func (e *Engine) handlePerft(enc *uci.Encoder, depth int) error {
+	var total uint64
+	for _, c := range core.PerftDivide(e.game.Position(), depth) {
+		total += c.Nodes
+		if err := enc.WriteMessage(&uci.PerftMove{Move: c.Move.String(), Nodes: int(c.Nodes)}); err != nil {
+			return err
+		}
+	}
+	return enc.WriteMessage(&uci.PerftTotal{Nodes: int(total)})
+}
+
+// searchMoves returns the moves to restrict the search to, as requested by msg.
+//
+// As a convenience, moves may be given in Standard Algebraic Notation as well
+// as UCI long algebraic notation. Moves that are invalid or illegal in the
+// current position are ignored.
+func (e *Engine) searchMoves(msg *uci.Go) []core.Move {
+	p := e.game.Position()
+
+	var moves []core.Move
+	for _, s := range msg.SearchMoves {
+		if m, err := p.ParseMoveLenient(s); err == nil {
+			moves = append(moves, m)
+		}
+	}
+	return moves
+}
+
+// searchLimits returns the maximum depth and time to search for, as requested
+// by msg. A budget of 0 means there is no time limit.
+//
+// Depth and time limits can be combined. If msg has neither, nor a clock for
+// turn, the search is infinite and only ends when stopped.
+func searchLimits(msg *uci.Go, turn core.Color) (depth int, budget time.Duration) {
+	if msg.Infinite {
+		return search.MaxDepth, 0
+	}
+
+	depth = search.MaxDepth
+	if msg.Depth > 0 {
+		depth = min(msg.Depth, search.MaxDepth)
+	}
+
+	left, inc := msg.WTime, msg.WInc
+	if turn == core.Black {
+		left, inc = msg.BTime, msg.BInc
+	}
+
+	switch {
+	case msg.MoveTime > 0:
+		budget = msg.MoveTime
+	case left > 0:
+		budget = moveBudget(left, inc, msg.MovesToGo)
+	}
+	return depth, budget
+}
+
+// uciScore converts a search score to a UCI score.
+func uciScore(score int) *uci.Score {
+	switch {
+	case score > search.Mate-search.MaxDepth:
+		return &uci.Score{Mate: (search.Mate - score + 1) / 2}
+	case score < -search.Mate+search.MaxDepth:
+		return &uci.Score{Mate: -(search.Mate + score) / 2}
+	default:
+		return &uci.Score{CP: score}
+	}
+}
+
+// nps returns the number of nodes searched per second.
+func nps(stats search.Stats) int {
+	if stats.Time <= 0 {
+		return 0
+	}
+	return int(float64(stats.Nodes) / stats.Time.Seconds())
+}
+
+// stopSearch stops the running search, if any, and waits for it to report
+// the best move.
+func (e *Engine) stopSearch() error {
+	if e.done == nil {
+		return nil
+	}
+	e.cancel()
+	err := <-e.done
+	e.cancel, e.done = nil, nil
+	return err
+}
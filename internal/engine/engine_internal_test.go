@@ -0,0 +1,136 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/clfs/they/internal/core"
+	"github.com/clfs/they/internal/search"
+	"github.com/clfs/they/internal/uci"
+)
+
+// longGame returns a legal game of n plies in UCI long algebraic notation.
+// The knights shuffle back and forth, with pawn pushes along the way to keep
+// the halfmove clock low.
+func longGame(n int) []string {
+	var moves []string
+	for f := 'a'; f <= 'h'; f++ {
+		moves = append(moves, string(f)+"2"+string(f)+"4", string(f)+"7"+string(f)+"5")
+		for range 9 {
+			moves = append(moves, "g1f3", "g8f6", "f3g1", "f6g8")
+		}
+	}
+	return moves[:n]
+}
+
+func TestEngine_handlePosition_longGame(t *testing.T) {
+	moves := longGame(300)
+
+	want := core.NewPosition()
+	for _, s := range moves {
+		m, err := want.ParseMove(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want.Move(m)
+	}
+
+	e := New()
+	if err := e.handlePosition(&uci.Position{Startpos: true, Moves: moves}); err != nil {
+		t.Fatalf("handlePosition: %v", err)
+	}
+	got := e.game.Position()
+	if got != want {
+		t.Errorf("handlePosition: got %q, want %q", got.FEN(), want.FEN())
+	}
+	if got.Plies != 300 {
+		t.Errorf("handlePosition: got %d plies, want 300", got.Plies)
+	}
+}
+
+func TestEngine_handlePosition(t *testing.T) {
+	e := New()
+	if err := e.handlePosition(&uci.Position{Startpos: true, Moves: []string{"e2e4", "e7e5", "g1f3"}}); err != nil {
+		t.Fatalf("handlePosition: %v", err)
+	}
+	const want = "rnbqkbnr/pppp1ppp/8/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq - 1 2"
+	p := e.game.Position()
+	if got := p.FEN(); got != want {
+		t.Errorf("handlePosition: got %q, want %q", got, want)
+	}
+
+	// A bad move is an error, and the position stays as it was.
+	for _, moves := range [][]string{{"e2e4", "e2e4"}, {"e2e4", "hello"}} {
+		if err := e.handlePosition(&uci.Position{Startpos: true, Moves: moves}); err == nil {
+			t.Errorf("handlePosition with moves %q: got nil error", moves)
+		}
+		p := e.game.Position()
+		if got := p.FEN(); got != want {
+			t.Errorf("handlePosition with moves %q: position changed to %q", moves, got)
+		}
+	}
+}
+
+func TestSearchLimits(t *testing.T) {
+	tests := []struct {
+		name       string
+		msg        uci.Go
+		turn       core.Color
+		wantDepth  int
+		wantBudget time.Duration
+	}{
+		{"infinite", uci.Go{Infinite: true}, core.White, search.MaxDepth, 0},
+		{"bare", uci.Go{}, core.White, search.MaxDepth, 0},
+		{"depth", uci.Go{Depth: 4}, core.White, 4, 0},
+		{"deep", uci.Go{Depth: 1000}, core.White, search.MaxDepth, 0},
+		{"movetime", uci.Go{MoveTime: time.Second}, core.White, search.MaxDepth, time.Second},
+		{
+			"depth and clock",
+			uci.Go{Depth: 4, WTime: 30 * time.Second, BTime: time.Minute},
+			core.White,
+			4,
+			moveBudget(30*time.Second, 0, 0),
+		},
+		{
+			"depth and clock for black",
+			uci.Go{Depth: 4, WTime: 30 * time.Second, BTime: time.Minute},
+			core.Black,
+			4,
+			moveBudget(time.Minute, 0, 0),
+		},
+	}
+
+	for _, test := range tests {
+		depth, budget := searchLimits(&test.msg, test.turn)
+		if depth != test.wantDepth || budget != test.wantBudget {
+			t.Errorf("%s: searchLimits: got %d, %v, want %d, %v", test.name, depth, budget, test.wantDepth, test.wantBudget)
+		}
+	}
+}
+
+func TestOptions_depth(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		msg  uci.Go
+		want int
+	}{
+		{"unset", Options{}, uci.Go{Depth: 30}, 30},
+		{"max", Options{MaxDepth: 12}, uci.Go{Depth: 30}, 12},
+		{"under max", Options{MaxDepth: 12}, uci.Go{Depth: 5}, 5},
+		{"min", Options{MinDepth: 6}, uci.Go{Depth: 2}, 6},
+		{"default", Options{DefaultDepth: 8}, uci.Go{}, 8},
+		{"default with clock", Options{DefaultDepth: 8}, uci.Go{WTime: time.Minute}, 8},
+		{"default ignored", Options{DefaultDepth: 8}, uci.Go{Depth: 3}, 3},
+		{"default for infinite", Options{DefaultDepth: 8}, uci.Go{Infinite: true}, search.MaxDepth},
+		{"default over max", Options{DefaultDepth: 20, MaxDepth: 12}, uci.Go{}, 12},
+		{"min over limit", Options{MinDepth: 1000}, uci.Go{Depth: 3}, search.MaxDepth},
+	}
+
+	for _, test := range tests {
+		depth, _ := searchLimits(&test.msg, core.White)
+		if got := test.opts.depth(depth, &test.msg); got != test.want {
+			t.Errorf("%s: got depth %d, want %d", test.name, got, test.want)
+		}
+	}
+}
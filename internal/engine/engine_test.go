@@ -1,7 +1,482 @@
-package engine
+package engine_test
 
-import "testing"
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/clfs/they/internal/core"
+	"github.com/clfs/they/internal/engine"
+	"github.com/clfs/they/internal/engine/enginetest"
+	"github.com/clfs/they/internal/uci"
+)
 
 func TestNop(t *testing.T) {
 	t.Log("nop")
 }
+
+func TestEngine_Run_uci(t *testing.T) {
+	s := enginetest.Start(t, engine.New())
+
+	s.Send("uci")
+	s.Expect("id name they", "id author clfs", "uciok")
+	s.Send("isready")
+	s.Expect("readyok")
+	s.Send("quit")
+
+	if rest := s.Close(); len(rest) != 0 {
+		t.Errorf("got output %q after quit, want none", rest)
+	}
+}
+
+func TestEngine_Run_banner(t *testing.T) {
+	s := enginetest.Start(t, engine.NewWithOptions(engine.Options{Banner: true}))
+
+	s.Send("uci", "isready", "quit")
+	s.Expect(engine.Banner, "id name they", "id author clfs", "uciok", "readyok")
+
+	if rest := s.Close(); len(rest) != 0 {
+		t.Errorf("got output %q after quit, want none", rest)
+	}
+}
+
+func TestEngine_Run_goDepth(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		want string
+	}{
+		{"mate in one", "6k1/5ppp/8/8/8/8/5PPP/3R2K1 w - - 0 1", "d1d8"},
+		{"black mates in one", "3r2k1/8/8/8/8/8/5PPP/6K1 b - - 0 1", "d8d1"},
+		{"hanging queen", "4k3/8/8/3q4/8/8/8/3RK3 w - - 0 1", "d1d5"},
+		{"hanging queen for black", "3rk3/8/8/8/3Q4/8/8/4K3 b - - 0 1", "d8d4"},
+	}
+
+	for _, test := range tests {
+		s := enginetest.Start(t, engine.New())
+		s.Send("position fen "+test.fen, "go depth 3")
+		if got := s.BestMove(); got != test.want {
+			t.Errorf("%s: got bestmove %q, want %q", test.name, got, test.want)
+		}
+		s.Close()
+	}
+}
+
+func TestEngine_Run_positionFEN(t *testing.T) {
+	s := enginetest.Start(t, engine.New())
+
+	// After the moves, White mates on the back rank with Rd8#.
+	s.Send(
+		"position fen 6k1/5ppp/8/8/8/8/5PPP/3R2K1 b - - 0 1 moves g8f8 g1f1 f8g8",
+		"go depth 3",
+	)
+	if got := s.BestMove(); got != "d1d8" {
+		t.Errorf("got bestmove %q, want %q", got, "d1d8")
+	}
+
+	s.Close()
+}
+
+func TestEngine_Run_stopWhileIdle(t *testing.T) {
+	s := enginetest.Start(t, engine.New())
+
+	// With no search running, stop does nothing.
+	s.Send("stop")
+	s.ExpectQuiet(50 * time.Millisecond)
+
+	s.Send("uci", "isready")
+	s.Expect("id name they", "id author clfs", "uciok", "readyok")
+
+	// Nor does a second stop after a search has finished.
+	s.Send("position startpos", "go depth 1")
+	s.BestMove()
+	s.Send("stop", "isready")
+	s.Expect("readyok")
+
+	s.Close()
+}
+
+func TestEngine_Run_isReadyWhileSearching(t *testing.T) {
+	s := enginetest.Start(t, engine.New())
+
+	s.Send("position startpos", "go infinite", "isready")
+
+	// Info lines about the search may come first.
+	line := s.Next()
+	for strings.HasPrefix(line, "info ") {
+		line = s.Next()
+	}
+	if line != "readyok" {
+		t.Errorf("got %q, want readyok", line)
+	}
+	s.Send("stop")
+	s.BestMove()
+
+	s.Close()
+}
+
+func TestEngine_Run_bareGo(t *testing.T) {
+	s := enginetest.Start(t, engine.New())
+
+	// Without a clock, a bare go searches until stopped.
+	s.Send("position startpos", "go")
+	s.ExpectNoBestMove(200 * time.Millisecond)
+	s.Send("stop")
+	s.BestMove()
+
+	s.Close()
+}
+
+func TestEngine_Run_bareGoWithClock(t *testing.T) {
+	s := enginetest.Start(t, engine.New())
+
+	// With a clock, the engine decides when to stop by itself.
+	s.Send("position startpos", "go wtime 1000 btime 1000")
+	s.BestMove()
+
+	s.Close()
+}
+
+func TestEngine_Run_stopThenGo(t *testing.T) {
+	s := enginetest.Start(t, engine.New())
+
+	// Each player can mate on the back rank.
+	const (
+		white = "position fen 6k1/5ppp/8/8/8/8/5PPP/3R2K1 w - - 0 1"
+		black = "position fen 3r2k1/5ppp/8/8/8/8/5PPP/6K1 b - - 0 1"
+	)
+
+	var want []string
+	for range 5 {
+		s.Send(white, "go infinite", "stop")
+		want = append(want, "d1d8")
+
+		// A new go stops the running search too.
+		s.Send(black, "go infinite", "go depth 2")
+		want = append(want, "d8d1", "d8d1")
+	}
+
+	for i, w := range want {
+		if got := s.BestMove(); got != w {
+			t.Errorf("bestmove %d: got %q, want %q", i, got, w)
+		}
+	}
+
+	s.Close()
+}
+
+func TestEngine_Run_blankLines(t *testing.T) {
+	s := enginetest.Start(t, engine.New())
+
+	s.Send("", "uci", "", "   ")
+	s.Expect("id name they", "id author clfs", "uciok")
+	s.Send("\t", "isready", "")
+	s.Expect("readyok")
+
+	s.Close()
+}
+
+func TestEngine_Run_badPosition(t *testing.T) {
+	s := enginetest.Start(t, engine.New())
+
+	s.Send("position startpos moves e2e4 e7e5")
+	for _, bad := range []string{
+		"position startpos moves e2e5",
+		"position startpos moves e2e4 hello",
+		"position fen not a fen",
+	} {
+		s.Send(bad)
+		if got := s.Next(); !strings.HasPrefix(got, "info string ") {
+			t.Errorf("%s: got %q, want an info string", bad, got)
+		}
+		s.Send("isready")
+		s.Expect("readyok")
+	}
+
+	// The engine keeps the last good position, where White has 29 moves.
+	s.Send("go perft 1")
+	for range 29 {
+		s.Next()
+	}
+	s.Expect("Nodes searched: 29")
+
+	s.Close()
+}
+
+func TestEngine_Run_searchMoves(t *testing.T) {
+	tests := []struct {
+		name     string
+		position string
+		goCmd    string
+		want     string
+	}{
+		{"coordinate", "position startpos", "go depth 2 searchmoves g1f3", "g1f3"},
+		{"SAN", "position startpos", "go depth 2 searchmoves Nf3", "g1f3"},
+		{
+			// Rd8# is best, but only the king may move.
+			"skips mate",
+			"position fen 6k1/5ppp/8/8/8/8/5PPP/3R2K1 w - - 0 1",
+			"go depth 3 searchmoves Kf1",
+			"g1f1",
+		},
+	}
+	for _, test := range tests {
+		s := enginetest.Start(t, engine.New())
+		s.Send(test.position, test.goCmd)
+		if got := s.BestMove(); got != test.want {
+			t.Errorf("%s: got bestmove %q, want %q", test.name, got, test.want)
+		}
+		s.Close()
+	}
+}
+
+func TestEngine_Run_goPerft(t *testing.T) {
+	s := enginetest.Start(t, engine.New())
+
+	s.Send("position startpos", "go perft 2")
+
+	total := 0
+	for range 20 {
+		var m uci.PerftMove
+		line := s.Next()
+		if err := m.UnmarshalText([]byte(line)); err != nil {
+			t.Fatalf("got line %q, want a perft move count", line)
+		}
+		if m.Nodes != 20 {
+			t.Errorf("got %d nodes below %s, want 20", m.Nodes, m.Move)
+		}
+		total += m.Nodes
+	}
+	if total != 400 {
+		t.Errorf("got %d nodes in total, want 400", total)
+	}
+	s.Expect("Nodes searched: 400")
+
+	s.Close()
+}
+
+func TestEngine_Run_eof(t *testing.T) {
+	// The input ends without a "quit" command, like when a GUI exits.
+	r := strings.NewReader("uci\nisready\nposition startpos\ngo depth 2\n")
+	if err := engine.New().Run(r, io.Discard); err != nil {
+		t.Errorf("Run: got %v, want nil", err)
+	}
+}
+
+func TestEngine_Run_readError(t *testing.T) {
+	want := errors.New("read failed")
+	r := io.MultiReader(strings.NewReader("uci\n"), iotest.ErrReader(want))
+	if err := engine.New().Run(r, io.Discard); !errors.Is(err, want) {
+		t.Errorf("Run: got %v, want %v", err, want)
+	}
+}
+
+func TestEngine_Run_forcedMove(t *testing.T) {
+	s := enginetest.Start(t, engine.New())
+
+	// Black's only legal move is Kg7. Searching it to depth 30 would take far
+	// too long, so the engine must answer without a deep search.
+	s.Send("position fen R5k1/5p1p/8/8/8/8/8/6K1 b - - 0 1", "go depth 30")
+	if got := s.BestMove(); got != "g8g7" {
+		t.Errorf("got bestmove %q, want %q", got, "g8g7")
+	}
+
+	s.Close()
+}
+
+func TestEngine_Run_setOption(t *testing.T) {
+	s := enginetest.Start(t, engine.New())
+
+	// Options may arrive before the handshake, and unknown ones are ignored.
+	s.Send("setoption name NoSuchOption value 42", "isready")
+	s.Expect("readyok")
+	s.Send("uci")
+	s.Expect("id name they", "id author clfs", "uciok")
+	s.Send("setoption name Another One", "isready")
+	s.Expect("readyok")
+
+	s.Close()
+}
+
+func TestEngine_Run_depthWithClock(t *testing.T) {
+	s := enginetest.Start(t, engine.New())
+
+	// Depth 20 would take far too long, but the clock cuts the search short.
+	s.Send("position startpos moves e2e4", "go depth 20 wtime 300 btime 300")
+	start := time.Now()
+	got := s.BestMove()
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("got bestmove after %v, want well within the clock", elapsed)
+	}
+
+	p, _, err := core.Replay("startpos", []string{"e2e4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m, err := p.ParseMoveLenient(got); err != nil {
+		t.Errorf("got bestmove %q: %v", got, err)
+	} else if m.String() != got {
+		t.Errorf("got bestmove %q, want long algebraic notation", got)
+	}
+
+	s.Close()
+}
+
+func TestEngine_Run_gameOver(t *testing.T) {
+	tests := []struct {
+		name     string
+		position string
+	}{
+		{"kings only", "position fen 4k3/8/8/8/8/8/8/4K3 w - - 0 1"},
+		{"capture leaves kings only", "position fen 4k3/8/8/8/8/8/3q4/4K3 w - - 0 1 moves e1d2"},
+		{"checkmate", "position startpos moves f2f3 e7e5 g2g4 d8h4"},
+	}
+
+	for _, test := range tests {
+		s := enginetest.Start(t, engine.New())
+		s.Send(test.position, "go depth 3")
+		if got := s.BestMove(); got != "0000" {
+			t.Errorf("%s: got bestmove %q, want %q", test.name, got, "0000")
+		}
+		s.Close()
+	}
+}
+
+func TestEngine_Run_maxDepth(t *testing.T) {
+	s := enginetest.Start(t, engine.NewWithOptions(engine.Options{MaxDepth: 12}))
+
+	// With only the kings able to move, depth 12 is quick to search.
+	s.Send("position fen k7/p7/P7/8/8/8/8/K7 w - - 0 1", "go depth 30")
+
+	// The summary before the best move reports the depth reached.
+	var info uci.Info
+	for line := s.Next(); !strings.HasPrefix(line, "bestmove"); line = s.Next() {
+		if err := info.UnmarshalText([]byte(line)); err != nil {
+			t.Fatalf("got %q, want info", line)
+		}
+	}
+	if info.Depth != 12 {
+		t.Errorf("got depth %d, want 12", info.Depth)
+	}
+
+	s.Close()
+}
+
+func TestEngine_Run_uciNewGame(t *testing.T) {
+	s := enginetest.Start(t, engine.New())
+
+	// A new game may be announced before the handshake.
+	s.Send("ucinewgame", "uci")
+	s.Expect("id name they", "id author clfs", "uciok")
+
+	// The previous game ended in checkmate, but after "ucinewgame" the engine
+	// searches from the starting position.
+	s.Send("position startpos moves f2f3 e7e5 g2g4 d8h4", "go depth 1")
+	if got := s.BestMove(); got != "0000" {
+		t.Errorf("got bestmove %q, want %q", got, "0000")
+	}
+	s.Send("ucinewgame", "go depth 1")
+	got := s.BestMove()
+
+	p := core.NewPosition()
+	if _, err := p.ParseMoveLenient(got); err != nil {
+		t.Errorf("got bestmove %q: %v", got, err)
+	}
+
+	s.Close()
+}
+
+func TestEngine_Run_infoBeforeBestMove(t *testing.T) {
+	tests := []struct {
+		name string
+		cmds []string
+	}{
+		{"search", []string{"position startpos", "go depth 2"}},
+		{"forced move", []string{"position fen R5k1/5p1p/8/8/8/8/8/6K1 b - - 0 1", "go depth 30"}},
+		{"checkmate", []string{"position startpos moves f2f3 e7e5 g2g4 d8h4", "go depth 3"}},
+		{"draw", []string{"position fen 4k3/8/8/8/8/8/8/4K3 w - - 0 1", "go depth 3"}},
+		{"stopped", []string{"position startpos", "go infinite", "stop"}},
+	}
+
+	for _, test := range tests {
+		s := enginetest.Start(t, engine.New())
+		s.Send(test.cmds...)
+
+		var prev string
+		for line := s.Next(); ; line = s.Next() {
+			if strings.HasPrefix(line, "bestmove") {
+				break
+			}
+			prev = line
+		}
+		if !strings.HasPrefix(prev, "info ") {
+			t.Errorf("%s: got %q before bestmove, want an info line", test.name, prev)
+		}
+
+		s.Close()
+	}
+}
+
+func TestEngine_Run_pv(t *testing.T) {
+	s := enginetest.Start(t, engine.New())
+	s.Send("position startpos", "go depth 4")
+
+	var last uci.Info
+	for line := s.Next(); !strings.HasPrefix(line, "bestmove"); line = s.Next() {
+		if err := last.UnmarshalText([]byte(line)); err != nil {
+			t.Fatalf("got line %q, want an info line", line)
+		}
+	}
+
+	// The principal variation is a legal line, longer than the best move
+	// alone, as found in the transposition table.
+	if len(last.PV) < 2 {
+		t.Fatalf("got PV %q, want at least 2 moves", last.PV)
+	}
+	if _, _, err := core.Replay("startpos", last.PV); err != nil {
+		t.Errorf("got PV %q: %v", last.PV, err)
+	}
+
+	s.Close()
+}
+
+func TestEngine_Run_progress(t *testing.T) {
+	s := enginetest.Start(t, engine.New())
+
+	// The table fills enough to show in the sample by depth 6.
+	s.Send("position fen 8/8/4k3/8/8/3K4/8/R7 w - - 0 1", "go depth 6")
+
+	var infos []uci.Info
+	for line := s.Next(); !strings.HasPrefix(line, "bestmove"); line = s.Next() {
+		var info uci.Info
+		if err := info.UnmarshalText([]byte(line)); err != nil {
+			t.Fatalf("got line %q, want an info line", line)
+		}
+		infos = append(infos, info)
+	}
+
+	// One line per iteration, then the summary.
+	if len(infos) != 7 {
+		t.Fatalf("got %d info lines, want 7", len(infos))
+	}
+	for i, info := range infos[:6] {
+		if info.Depth != i+1 {
+			t.Errorf("info line %d: got depth %d, want %d", i, info.Depth, i+1)
+		}
+		if len(info.PV) == 0 {
+			t.Errorf("info line %d: got no PV", i)
+		}
+		// The table fills as the search goes on.
+		if i > 0 && info.HashFull < infos[i-1].HashFull {
+			t.Errorf("info line %d: got hashfull %d, want at least %d", i, info.HashFull, infos[i-1].HashFull)
+		}
+	}
+	if got := infos[5].HashFull; got == 0 {
+		t.Error("got hashfull 0 at depth 6")
+	}
+
+	s.Close()
+}
@@ -0,0 +1,158 @@
+// Package enginetest provides utilities for testing the engine.
+package enginetest
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/clfs/they/internal/engine"
+)
+
+// Timeout bounds how long a [Session] waits for the engine.
+const Timeout = 5 * time.Second
+
+// A Session drives an engine running in the background over in-memory pipes.
+type Session struct {
+	tb    testing.TB
+	in    *io.PipeWriter
+	lines chan string
+	err   chan error
+}
+
+// Start runs e in the background. If the test finishes without calling
+// [Session.Close], the engine's input is closed automatically.
+func Start(tb testing.TB, e *engine.Engine) *Session {
+	tb.Helper()
+
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+
+	s := &Session{
+		tb: tb,
+		in: inW,
+		// Buffer output so the engine never waits on the test to read it.
+		lines: make(chan string, 1000),
+		err:   make(chan error, 1),
+	}
+
+	go func() {
+		s.err <- e.Run(inR, outW)
+		outW.Close()
+	}()
+
+	go func() {
+		defer close(s.lines)
+		sc := bufio.NewScanner(outR)
+		for sc.Scan() {
+			s.lines <- sc.Text()
+		}
+	}()
+
+	tb.Cleanup(func() { inW.Close() })
+
+	return s
+}
+
+// Send sends lines to the engine.
+func (s *Session) Send(lines ...string) {
+	s.tb.Helper()
+	for _, line := range lines {
+		if _, err := io.WriteString(s.in, line+"\n"); err != nil {
+			s.tb.Fatalf("send %q: %v", line, err)
+		}
+	}
+}
+
+// Next returns the next line from the engine.
+func (s *Session) Next() string {
+	s.tb.Helper()
+	select {
+	case line, ok := <-s.lines:
+		if !ok {
+			s.tb.Fatal("expected a line, got end of output")
+		}
+		return line
+	case <-time.After(Timeout):
+		s.tb.Fatal("timed out waiting for a line")
+	}
+	return ""
+}
+
+// Expect fails the test unless the next lines from the engine are want.
+func (s *Session) Expect(want ...string) {
+	s.tb.Helper()
+	for _, w := range want {
+		if got := s.Next(); got != w {
+			s.tb.Fatalf("got %q, want %q", got, w)
+		}
+	}
+}
+
+// ExpectQuiet fails the test if the engine writes anything within d.
+func (s *Session) ExpectQuiet(d time.Duration) {
+	s.tb.Helper()
+	select {
+	case got := <-s.lines:
+		s.tb.Fatalf("got %q, want no output", got)
+	case <-time.After(d):
+	}
+}
+
+// ExpectNoBestMove fails the test if the engine writes anything but info lines
+// within d.
+func (s *Session) ExpectNoBestMove(d time.Duration) {
+	s.tb.Helper()
+	timeout := time.After(d)
+	for {
+		select {
+		case got := <-s.lines:
+			if !strings.HasPrefix(got, "info ") {
+				s.tb.Fatalf("got %q, want only info lines", got)
+			}
+		case <-timeout:
+			return
+		}
+	}
+}
+
+// BestMove skips info lines from the engine and returns the move in the next
+// bestmove line.
+func (s *Session) BestMove() string {
+	s.tb.Helper()
+	for {
+		line := s.Next()
+		if strings.HasPrefix(line, "info ") {
+			continue
+		}
+		move, ok := strings.CutPrefix(line, "bestmove ")
+		if !ok {
+			s.tb.Fatalf("got %q, want bestmove", line)
+		}
+		return move
+	}
+}
+
+// Close closes the engine's input, waits for the engine to stop, and returns
+// its remaining output.
+func (s *Session) Close() []string {
+	s.tb.Helper()
+	s.in.Close()
+
+	select {
+	case err := <-s.err:
+		if err != nil {
+			s.tb.Fatalf("Run: %v", err)
+		}
+	case <-time.After(Timeout):
+		s.tb.Fatal("timed out waiting for Run to return")
+	}
+
+	var rest []string
+	for line := range s.lines {
+		rest = append(rest, line)
+	}
+	return rest
+}
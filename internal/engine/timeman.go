@@ -0,0 +1,29 @@
+package engine
+
+import "time"
+
+const (
+	// defaultMovesToGo is the assumed number of moves left in the game when
+	// the time control does not say.
+	defaultMovesToGo = 30
+
+	// moveOverhead is time reserved for communication with the GUI.
+	moveOverhead = 50 * time.Millisecond
+)
+
+// moveBudget returns how long to think about a move, given the time left on
+// the clock, the increment per move, and the number of moves until the next
+// time control (0 if unknown).
+func moveBudget(left, inc time.Duration, movesToGo int) time.Duration {
+	if movesToGo <= 0 {
+		movesToGo = defaultMovesToGo
+	}
+
+	budget := left/time.Duration(movesToGo) + inc/2
+
+	// Never plan to use time that is not on the clock.
+	budget = min(budget, left-moveOverhead)
+
+	// Always think a little, even when nearly out of time.
+	return max(budget, time.Millisecond)
+}
@@ -0,0 +1,53 @@
+package engine
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMoveBudget(t *testing.T) {
+	tests := []struct {
+		name      string
+		left, inc time.Duration
+		movesToGo int
+		want      time.Duration
+	}{
+		{
+			name: "sudden death",
+			left: 30 * time.Second,
+			want: time.Second,
+		},
+		{
+			name: "increment",
+			left: 30 * time.Second,
+			inc:  2 * time.Second,
+			want: 2 * time.Second,
+		},
+		{
+			name:      "moves to go",
+			left:      10 * time.Second,
+			movesToGo: 5,
+			want:      2 * time.Second,
+		},
+		{
+			name:      "last move before time control",
+			left:      time.Second,
+			movesToGo: 1,
+			want:      time.Second - moveOverhead,
+		},
+		{
+			name: "nearly flagged",
+			left: 10 * time.Millisecond,
+			want: time.Millisecond,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := moveBudget(test.left, test.inc, test.movesToGo)
+			if got != test.want {
+				t.Errorf("moveBudget(%v, %v, %d): got %v, want %v", test.left, test.inc, test.movesToGo, got, test.want)
+			}
+		})
+	}
+}
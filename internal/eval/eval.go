@@ -0,0 +1,296 @@
+// Package eval implements static evaluation of positions.
+package eval
+
+import (
+	"math/bits"
+
+	"github.com/clfs/they/internal/core"
+)
+
+// Weights configure the positional terms of the evaluation, in centipawns.
+type Weights struct {
+	// The value of each piece type, indexed by [core.PieceType]. The king's
+	// value is ignored. If all values are zero, [core.PieceValues] is used.
+	PieceValues [6]int
+
+	// Bonus for the player whose turn it is.
+	Tempo int
+
+	// Bonus for having two or more bishops.
+	BishopPair int
+
+	// Bonus for each rook on a file with no pawns.
+	RookOpenFile int
+
+	// Bonus for each rook on a file with enemy pawns but no friendly pawns.
+	RookHalfOpenFile int
+
+	// Bonus for each knight on an outpost: a square in the enemy half of the
+	// board, defended by a friendly pawn, that no enemy pawn can attack.
+	KnightOutpost int
+
+	// Bonus for each passed pawn, indexed by its rank from the perspective of
+	// its owner.
+	PassedPawn [8]int
+
+	// Extra bonus for each passed pawn defended by a friendly pawn, as a
+	// multiple of its PassedPawn bonus in percent.
+	PassedPawnProtected int
+
+	// Extra bonus for each passed pawn with a friendly passed pawn on an
+	// adjacent file, as a multiple of its PassedPawn bonus in percent.
+	PassedPawnConnected int
+
+	// Bonus for each pawn, indexed by its square from White's perspective.
+	// Black's squares are mirrored vertically, so Black's pawns use the same
+	// table.
+	PawnSquares [64]int
+
+	// Bonus for each knight, indexed like PawnSquares.
+	KnightSquares [64]int
+}
+
+// DefaultWeights are the weights used by [Evaluate].
+var DefaultWeights = Weights{
+	PieceValues:      core.PieceValues(),
+	Tempo:            10,
+	BishopPair:       30,
+	RookOpenFile:     20,
+	RookHalfOpenFile: 10,
+	KnightOutpost:    25,
+	PassedPawn: [8]int{
+		core.Rank2: 5,
+		core.Rank3: 10,
+		core.Rank4: 20,
+		core.Rank5: 35,
+		core.Rank6: 60,
+		core.Rank7: 100,
+	},
+	PassedPawnProtected: 50,
+	PassedPawnConnected: 25,
+
+	// Pawns are encouraged to take the center and discouraged from leaving
+	// the king's shelter. Advanced pawns are rewarded through PassedPawn
+	// instead.
+	PawnSquares: [64]int{
+		0, 0, 0, 0, 0, 0, 0, 0, // Rank 1
+		5, 10, 10, -20, -20, 10, 10, 5,
+		5, -5, -10, 0, 0, -10, -5, 5,
+		0, 0, 0, 20, 20, 0, 0, 0,
+		5, 5, 10, 25, 25, 10, 5, 5,
+		10, 10, 20, 30, 30, 20, 10, 10,
+		20, 20, 20, 20, 20, 20, 20, 20,
+		0, 0, 0, 0, 0, 0, 0, 0, // Rank 8
+	},
+
+	// Knights are strongest in the center and weakest in the corners.
+	KnightSquares: [64]int{
+		-50, -40, -30, -30, -30, -30, -40, -50, // Rank 1
+		-40, -20, 0, 5, 5, 0, -20, -40,
+		-30, 5, 10, 15, 15, 10, 5, -30,
+		-30, 0, 15, 20, 20, 15, 0, -30,
+		-30, 5, 15, 20, 20, 15, 5, -30,
+		-30, 0, 10, 15, 15, 10, 0, -30,
+		-40, -20, 0, 0, 0, 0, -20, -40,
+		-50, -40, -30, -30, -30, -30, -40, -50, // Rank 8
+	},
+}
+
+// Evaluate returns the static evaluation of p in centipawns, from the
+// perspective of the player whose turn it is, using [DefaultWeights].
+func Evaluate(p *core.Position) int {
+	return DefaultWeights.Evaluate(p)
+}
+
+// Evaluate returns the static evaluation of p in centipawns, from the
+// perspective of the player whose turn it is, using w.
+func (w *Weights) Evaluate(p *core.Position) int {
+	score := w.side(p, core.White) - w.side(p, core.Black)
+
+	if p.Turn == core.Black {
+		score = -score
+	}
+
+	// The player whose turn it is can improve their position first.
+	score += w.Tempo
+
+	return score * drawScale(p) / 100
+}
+
+// drawScale returns the percentage of the evaluation of p to keep, given how
+// drawish its material is. Without pawns, a material advantage often isn't
+// enough to win: neither a lone minor piece nor two knights can force mate,
+// and endings like rook against rook or rook against bishop are usually drawn.
+// With pawns, bishops of opposite colors alone halve the evaluation, since
+// neither bishop can contest the squares of the other, and an extra pawn or
+// two is often blockaded.
+func drawScale(p *core.Position) int {
+	white, black := pieceMaterial(p, core.White), pieceMaterial(p, core.Black)
+
+	if pawns(p, core.White)|pawns(p, core.Black) != 0 {
+		if white == core.Bishop.Value() && black == white && hasOppositeBishops(p) {
+			return 50
+		}
+		return 100
+	}
+
+	strong, weak := max(white, black), min(white, black)
+	strongColor := core.White
+	if black > white {
+		strongColor = core.Black
+	}
+	knights := p.Board.Pieces(core.NewPiece(strongColor, core.Knight))
+
+	switch {
+	case strong < core.Rook.Value():
+		// A lone minor piece, or nothing at all.
+		return 0
+	case weak == 0 && strong == 2*core.Knight.Value() && knights.Count() == 2:
+		return 0
+	case strong-weak <= core.Bishop.Value():
+		return 10
+	default:
+		return 100
+	}
+}
+
+// hasOppositeBishops returns true if each player has a single bishop, and the
+// bishops are on squares of opposite colors.
+func hasOppositeBishops(p *core.Position) bool {
+	white := p.Board.Pieces(core.NewPiece(core.White, core.Bishop))
+	black := p.Board.Pieces(core.NewPiece(core.Black, core.Bishop))
+	if white.Count() != 1 || black.Count() != 1 {
+		return false
+	}
+	w := core.Square(bits.TrailingZeros64(uint64(white)))
+	b := core.Square(bits.TrailingZeros64(uint64(black)))
+	return isLightSquare(w) != isLightSquare(b)
+}
+
+// isLightSquare returns true if s is a light square, like H1.
+func isLightSquare(s core.Square) bool {
+	return (int(s.File())+int(s.Rank()))%2 == 1
+}
+
+// pieceMaterial returns the material of color c in centipawns, not counting
+// pawns or the king.
+func pieceMaterial(p *core.Position, c core.Color) int {
+	material := 0
+	for pt := core.Knight; pt < core.King; pt++ {
+		b := p.Board.Pieces(core.NewPiece(c, pt))
+		material += pt.Value() * b.Count()
+	}
+	return material
+}
+
+// side returns the evaluation of p in centipawns for color c alone.
+func (w *Weights) side(p *core.Position, c core.Color) int {
+	score := w.material(p, c)
+	score += pieceSquares(p, c, core.Pawn, &w.PawnSquares)
+	score += pieceSquares(p, c, core.Knight, &w.KnightSquares)
+
+	if bishops := p.Board.Pieces(core.NewPiece(c, core.Bishop)); bishops.Count() >= 2 {
+		score += w.BishopPair
+	}
+
+	knights := p.Board.Pieces(core.NewPiece(c, core.Knight))
+	for b := knights; b != 0; b &= b - 1 {
+		if isOutpost(p, c, core.Square(bits.TrailingZeros64(uint64(b)))) {
+			score += w.KnightOutpost
+		}
+	}
+
+	rooks := p.Board.Pieces(core.NewPiece(c, core.Rook))
+	for b := rooks; b != 0; b &= b - 1 {
+		f := core.Square(bits.TrailingZeros64(uint64(b))).File()
+		switch {
+		case isOpenFile(p, f):
+			score += w.RookOpenFile
+		case isHalfOpenFile(p, c, f):
+			score += w.RookHalfOpenFile
+		}
+	}
+
+	return score + w.passedPawns(p, c) + mopUp(p, c)
+}
+
+// material returns the material of color c in centipawns, not counting the
+// king. Unless w overrides the piece values, it is the running count kept by
+// [core.Position.Material].
+func (w *Weights) material(p *core.Position, c core.Color) int {
+	if w.PieceValues == [6]int{} || w.PieceValues == core.PieceValues() {
+		return p.Material(c)
+	}
+	material := 0
+	for pt := core.Pawn; pt < core.King; pt++ {
+		b := p.Board.Pieces(core.NewPiece(c, pt))
+		material += w.PieceValues[pt] * b.Count()
+	}
+	return material
+}
+
+// pieceSquares returns the sum of the bonuses in table for the pieces of color
+// c and type pt. The table is from White's perspective.
+func pieceSquares(p *core.Position, c core.Color, pt core.PieceType, table *[64]int) int {
+	score := 0
+	for b := p.Board.Pieces(core.NewPiece(c, pt)); b != 0; b &= b - 1 {
+		s := bits.TrailingZeros64(uint64(b))
+		if c == core.Black {
+			s ^= 56 // Mirror the rank.
+		}
+		score += table[s]
+	}
+	return score
+}
+
+// passedPawns returns the bonus for the passed pawns of color c.
+func (w *Weights) passedPawns(p *core.Position, c core.Color) int {
+	passed := passedPawns(p, c)
+
+	score := 0
+	for b := passed; b != 0; b &= b - 1 {
+		s := core.Square(bits.TrailingZeros64(uint64(b)))
+		bonus := w.PassedPawn[relativeRank(c, s.Rank())]
+		score += bonus
+		if isProtected(p, c, s) {
+			score += bonus * w.PassedPawnProtected / 100
+		}
+		if passed&core.AdjacentFiles(s.File()) != 0 {
+			score += bonus * w.PassedPawnConnected / 100
+		}
+	}
+	return score
+}
+
+// mopUp returns a bonus for color c when the other player has a lone king and c
+// has enough material to force mate. The bonus rewards driving the lone king to
+// the edge of the board and bringing c's king closer, which is how mate is
+// forced.
+//
+// Without this term, the search has no reason to make progress towards a mate
+// beyond its horizon.
+func mopUp(p *core.Position, c core.Color) int {
+	weak := p.Board.Black()
+	if c == core.Black {
+		weak = p.Board.White()
+	}
+	if weak.Count() != 1 {
+		return 0
+	}
+
+	if p.Material(c) < core.Rook.Value() {
+		return 0
+	}
+
+	strongKing, _ := p.Board.KingSquare(c)
+	weakKing, _ := p.Board.KingSquare(c.Other())
+
+	return 10*centerDistance(weakKing) + 4*(7-strongKing.Distance(weakKing))
+}
+
+// centerDistance returns the number of file and rank steps between s and the
+// four center squares. It is 0 in the center and 6 in the corners.
+func centerDistance(s core.Square) int {
+	f, r := int(s.File()), int(s.Rank())
+	return max(3-f, f-4) + max(3-r, r-4)
+}
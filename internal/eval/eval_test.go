@@ -0,0 +1,323 @@
+package eval
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/clfs/they/internal/core"
+)
+
+// mustParseFEN parses a position in FEN or fails the test.
+func mustParseFEN(t *testing.T, fen string) core.Position {
+	t.Helper()
+	p, err := core.ParseFEN(fen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestWeights_Evaluate_tempo(t *testing.T) {
+	w := Weights{Tempo: 15}
+
+	// The starting position is balanced, so only the tempo counts.
+	white := core.NewPosition()
+	black := mustParseFEN(t, "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR b KQkq - 0 1")
+
+	if got := w.Evaluate(&white); got != w.Tempo {
+		t.Errorf("White to move: got %d, want %d", got, w.Tempo)
+	}
+	if got := w.Evaluate(&black); got != w.Tempo {
+		t.Errorf("Black to move: got %d, want %d", got, w.Tempo)
+	}
+
+	// White is a knight up.
+	p := mustParseFEN(t, "r1bqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR b KQkq - 0 1")
+	if got, want := w.Evaluate(&p), -core.Knight.Value()+w.Tempo; got != want {
+		t.Errorf("Black to move, a knight down: got %d, want %d", got, want)
+	}
+}
+
+func TestWeights_Evaluate_bishopPair(t *testing.T) {
+	w := Weights{BishopPair: 50}
+
+	// White has two bishops, or a bishop and a knight, against Black's two
+	// knights.
+	pair := mustParseFEN(t, "1n2k1n1/pppppppp/8/8/8/8/PPPPPPPP/2B1KB2 w - - 0 1")
+	mixed := mustParseFEN(t, "1n2k1n1/pppppppp/8/8/8/8/PPPPPPPP/2B1K1N1 w - - 0 1")
+
+	gotPair, gotMixed := w.Evaluate(&pair), w.Evaluate(&mixed)
+	if gotPair <= gotMixed {
+		t.Errorf("bishop pair scored %d, want more than bishop and knight (%d)", gotPair, gotMixed)
+	}
+
+	material := core.Bishop.Value() - core.Knight.Value()
+	if got, want := gotPair-gotMixed, material+w.BishopPair; got != want {
+		t.Errorf("bishop pair is worth %d more, want %d", got, want)
+	}
+}
+
+func TestWeights_Evaluate_rookFiles(t *testing.T) {
+	w := Weights{RookOpenFile: 20, RookHalfOpenFile: 10}
+
+	// The same rook on the a-file, with the pawns shifted between the a-file
+	// and the b-file.
+	closed := mustParseFEN(t, "4k3/p7/8/8/8/8/P7/R3K3 w - - 0 1")
+	halfOpen := mustParseFEN(t, "4k3/p7/8/8/8/8/1P6/R3K3 w - - 0 1")
+	open := mustParseFEN(t, "4k3/1p6/8/8/8/8/1P6/R3K3 w - - 0 1")
+
+	base := w.Evaluate(&closed)
+	if got := w.Evaluate(&open); got != base+w.RookOpenFile {
+		t.Errorf("open file: got %d, want %d", got, base+w.RookOpenFile)
+	}
+	if got := w.Evaluate(&halfOpen); got != base+w.RookHalfOpenFile {
+		t.Errorf("half-open file: got %d, want %d", got, base+w.RookHalfOpenFile)
+	}
+}
+
+func TestWeights_Evaluate_passedPawns(t *testing.T) {
+	w := DefaultWeights
+
+	tests := []struct {
+		name         string
+		better, base string
+	}{
+		{
+			"advanced",
+			"4k3/8/3P4/8/8/8/8/4K3 w - - 0 1",
+			"4k3/8/8/8/8/3P4/8/4K3 w - - 0 1",
+		},
+		{
+			"advanced black",
+			"4k3/8/8/8/8/3p4/8/4K3 b - - 0 1",
+			"4k3/8/3p4/8/8/8/8/4K3 b - - 0 1",
+		},
+		{
+			"passed",
+			"4k3/8/1p6/8/7P/8/8/4K3 w - - 0 1",
+			"4k3/8/1p6/8/P7/8/8/4K3 w - - 0 1",
+		},
+		{
+			"protected",
+			"4k3/8/3P4/2P5/8/8/8/4K3 w - - 0 1",
+			"4k3/8/3P4/8/8/8/P7/4K3 w - - 0 1",
+		},
+		{
+			"connected",
+			"4k3/8/2PP4/8/8/8/8/4K3 w - - 0 1",
+			"4k3/8/P2P4/8/8/8/8/4K3 w - - 0 1",
+		},
+	}
+	for _, test := range tests {
+		better := mustParseFEN(t, test.better)
+		base := mustParseFEN(t, test.base)
+		if got, want := w.Evaluate(&better), w.Evaluate(&base); got <= want {
+			t.Errorf("%s: got %d, want more than %d", test.name, got, want)
+		}
+	}
+}
+
+func TestWeights_Evaluate_knightOutpost(t *testing.T) {
+	w := Weights{KnightOutpost: 25}
+
+	tests := []struct {
+		name string
+		fen  string
+		want bool
+	}{
+		{"outpost", "4k3/pp4pp/8/3N4/2P5/8/8/4K3 w - - 0 1", true},
+		{"black outpost", "4k3/8/8/2p5/3n4/8/PP4PP/4K3 b - - 0 1", true},
+		{"rim", "4k3/pp4pp/8/N7/2P5/8/8/4K3 w - - 0 1", false},
+		{"unprotected", "4k3/pp4pp/8/3N4/8/2P5/8/4K3 w - - 0 1", false},
+		{"attackable", "4k3/pp2p1pp/8/3N4/2P5/8/8/4K3 w - - 0 1", false},
+		{"own half", "4k3/pp4pp/8/8/2P5/3N4/8/4K3 w - - 0 1", false},
+	}
+	for _, test := range tests {
+		p := mustParseFEN(t, test.fen)
+		with := w.Evaluate(&p)
+		without := new(Weights).Evaluate(&p)
+		if got := with != without; got != test.want {
+			t.Errorf("%s: got outpost %t, want %t", test.name, got, test.want)
+		}
+	}
+
+	// A knight on an outpost beats the same knight on the rim.
+	outpost := mustParseFEN(t, tests[0].fen)
+	rim := mustParseFEN(t, tests[2].fen)
+	if got, want := w.Evaluate(&outpost), w.Evaluate(&rim); got <= want {
+		t.Errorf("outpost knight scored %d, want more than rim knight (%d)", got, want)
+	}
+}
+
+func TestEvaluate_drawish(t *testing.T) {
+	tests := []struct {
+		name    string
+		fen     string
+		drawish bool
+	}{
+		{"KNN vs K", "4k3/8/8/8/8/8/8/1N2K1N1 w - - 0 1", true},
+		{"KB vs K", "4k3/8/8/8/8/8/8/2B1K3 w - - 0 1", true},
+		{"KR vs KR", "r3k3/8/8/8/8/8/8/R3K3 w - - 0 1", true},
+		{"KR vs KB", "2b1k3/8/8/8/8/8/8/R3K3 w - - 0 1", true},
+		{"KB vs KB opposite colors", "2b1k3/8/8/8/8/8/8/4B1K1 w - - 0 1", true},
+		{"KB vs KB same colors", "2b1k3/8/8/8/8/8/8/5BK1 w - - 0 1", true},
+		{"KR vs K", "4k3/8/8/8/8/8/8/R3K3 w - - 0 1", false},
+		{"KQ vs KR", "r3k3/8/8/8/8/8/8/3QK3 w - - 0 1", false},
+		{"KRP vs KR", "r3k3/8/8/8/8/8/P7/R3K3 w - - 0 1", false},
+	}
+	for _, test := range tests {
+		p := mustParseFEN(t, test.fen)
+		got := Evaluate(&p)
+		if drawish := abs(got) <= core.Pawn.Value()/2; drawish != test.drawish {
+			t.Errorf("%s: got %d, want drawish %t", test.name, got, test.drawish)
+		}
+	}
+}
+
+func TestEvaluate_oppositeBishops(t *testing.T) {
+	// White is a pawn up in both, with bishops of the same and of opposite
+	// colors.
+	same := mustParseFEN(t, "2b1k3/8/8/8/8/8/P7/4KB2 w - - 0 1")
+	opposite := mustParseFEN(t, "3bk3/8/8/8/8/8/P7/4KB2 w - - 0 1")
+
+	s, o := Evaluate(&same), Evaluate(&opposite)
+	if o <= 0 || 2*o > s+1 {
+		t.Errorf("opposite bishops scored %d, want about half of same bishops (%d)", o, s)
+	}
+}
+
+// abs returns the absolute value of x.
+func abs(x int) int {
+	return max(x, -x)
+}
+
+func TestWeights_Evaluate_pieceSquares(t *testing.T) {
+	w := Weights{
+		PawnSquares:   DefaultWeights.PawnSquares,
+		KnightSquares: DefaultWeights.KnightSquares,
+	}
+
+	tests := []struct {
+		name          string
+		better, worse string
+	}{
+		{"central knight", "4k3/7p/8/8/3N4/8/7P/4K3 w - - 0 1", "4k3/7p/8/8/8/8/7P/N3K3 w - - 0 1"},
+		{"black central knight", "4k3/7p/8/3n4/8/8/7P/4K3 b - - 0 1", "n3k3/7p/8/8/8/8/7P/4K3 b - - 0 1"},
+		{"central pawn", "4k3/8/8/8/3P4/8/8/4K3 w - - 0 1", "4k3/8/8/8/8/8/3P4/4K3 w - - 0 1"},
+		{"black central pawn", "4k3/8/8/3p4/8/8/8/4K3 b - - 0 1", "4k3/3p4/8/8/8/8/8/4K3 b - - 0 1"},
+	}
+
+	for _, test := range tests {
+		better, worse := mustParseFEN(t, test.better), mustParseFEN(t, test.worse)
+		if got, want := w.Evaluate(&better), w.Evaluate(&worse); got <= want {
+			t.Errorf("%s: got %d, want more than %d", test.name, got, want)
+		}
+	}
+}
+
+// mirrorFEN returns fen with the board flipped vertically and the colors of
+// the pieces and the turn swapped.
+func mirrorFEN(fen string) string {
+	fields := strings.Fields(fen)
+	ranks := strings.Split(fields[0], "/")
+	slices.Reverse(ranks)
+	fields[0] = swapCase(strings.Join(ranks, "/"))
+	if fields[1] == "w" {
+		fields[1] = "b"
+	} else {
+		fields[1] = "w"
+	}
+	fields[2] = swapCase(fields[2])
+	if ep := fields[3]; ep != "-" {
+		fields[3] = ep[:1] + string('1'+'8'-ep[1])
+	}
+	return strings.Join(fields, " ")
+}
+
+// swapCase returns s with upper and lower case letters swapped.
+func swapCase(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsUpper(r) {
+			return unicode.ToLower(r)
+		}
+		return unicode.ToUpper(r)
+	}, s)
+}
+
+func TestEvaluate_symmetry(t *testing.T) {
+	fens := []string{
+		core.StartFEN,
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"rnbqkbnr/pppp1ppp/8/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq - 1 2",
+		"r1bqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR b KQkq - 0 1",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+		"4k3/8/8/8/8/8/8/R3K3 w - - 0 1",
+	}
+
+	// The mirrored position is the same position with the players' roles
+	// swapped, so the player to move scores it the same. From White's
+	// perspective, the score negates.
+	for _, fen := range fens {
+		p, q := mustParseFEN(t, fen), mustParseFEN(t, mirrorFEN(fen))
+		if got, want := Evaluate(&q), Evaluate(&p); got != want {
+			t.Errorf("Evaluate(%q): got %d, want %d as for %q", mirrorFEN(fen), got, want, fen)
+		}
+	}
+}
+
+func TestWeights_Evaluate_material(t *testing.T) {
+	// Both players have pawns, so the evaluation isn't scaled towards a draw.
+	fens := map[core.PieceType]string{
+		core.Pawn:   "4k3/pppp4/8/8/8/8/PPPP3P/4K3 w - - 0 1",
+		core.Knight: "4k3/pppp4/8/8/8/8/PPPP4/4K1N1 w - - 0 1",
+		core.Bishop: "4k3/pppp4/8/8/8/8/PPPP4/4KB2 w - - 0 1",
+		core.Rook:   "4k3/pppp4/8/8/8/8/PPPP4/4K2R w - - 0 1",
+		core.Queen:  "4k3/pppp4/8/8/8/8/PPPP4/3QK3 w - - 0 1",
+	}
+	custom := [6]int{
+		core.Pawn:   90,
+		core.Knight: 280,
+		core.Bishop: 310,
+		core.Rook:   480,
+		core.Queen:  1000,
+	}
+
+	// A bare material evaluation counts White's extra piece at its value.
+	values := core.PieceValues()
+	for pt, fen := range fens {
+		p := mustParseFEN(t, fen)
+		for _, pv := range [][6]int{{}, DefaultWeights.PieceValues, custom} {
+			w := Weights{PieceValues: pv}
+			want := pv[pt]
+			if want == 0 {
+				want = values[pt]
+			}
+			if got := w.Evaluate(&p); got != want {
+				t.Errorf("Evaluate(%q) with values %v: got %d, want %d", fen, pv, got, want)
+			}
+		}
+	}
+
+	// Changing the returned values doesn't change the shared table.
+	values[core.Knight] = 0
+	if core.Knight.Value() == 0 || core.PieceValues()[core.Knight] == 0 {
+		t.Error("changing PieceValues() changed the shared values")
+	}
+}
+
+func TestWeights_Evaluate_pieceValues(t *testing.T) {
+	// Evaluation and static exchange evaluation share core.PieceValues.
+	// White is a knight up, and its pawn can take another.
+	p := mustParseFEN(t, "4k3/pppp4/8/3n4/4P3/8/PPP5/1N2K1N1 w - - 0 1")
+	w := Weights{PieceValues: DefaultWeights.PieceValues}
+	if got, want := w.Evaluate(&p), core.PieceValues()[core.Knight]; got != want {
+		t.Errorf("Evaluate: got %d, want %d", got, want)
+	}
+
+	m := core.NewMove(core.E4, core.D5)
+	if got, want := p.SEE(m), core.Knight.Value(); got != want {
+		t.Errorf("SEE(%v): got %d, want %d", m, got, want)
+	}
+}
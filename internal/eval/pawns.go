@@ -0,0 +1,77 @@
+package eval
+
+import (
+	"math/bits"
+
+	"github.com/clfs/they/internal/core"
+)
+
+// pawns returns the pawns of color c.
+func pawns(p *core.Position, c core.Color) core.Bitboard {
+	return p.Board.Pieces(core.NewPiece(c, core.Pawn))
+}
+
+// isOpenFile returns true if f has no pawns.
+func isOpenFile(p *core.Position, f core.File) bool {
+	return (pawns(p, core.White)|pawns(p, core.Black))&f.Bitboard() == 0
+}
+
+// isHalfOpenFile returns true if f has pawns of the other player but none of
+// color c.
+func isHalfOpenFile(p *core.Position, c core.Color, f core.File) bool {
+	return pawns(p, c)&f.Bitboard() == 0 && pawns(p, c.Other())&f.Bitboard() != 0
+}
+
+// relativeRank returns r from the perspective of color c, so that each
+// player's pawns start on [core.Rank2].
+func relativeRank(c core.Color, r core.Rank) core.Rank {
+	if c == core.Black {
+		return core.Rank8 - r
+	}
+	return r
+}
+
+// ranksAhead returns the squares on ranks beyond r in the direction that pawns
+// of color c move.
+func ranksAhead(c core.Color, r core.Rank) core.Bitboard {
+	var b core.Bitboard
+	for x := core.Rank1; x <= core.Rank8; x++ {
+		if relativeRank(c, x) > relativeRank(c, r) {
+			b |= x.Bitboard()
+		}
+	}
+	return b
+}
+
+// passedPawns returns the pawns of color c that no pawn of the other player
+// can stop, because none is ahead of them on the same or an adjacent file.
+func passedPawns(p *core.Position, c core.Color) core.Bitboard {
+	theirs := pawns(p, c.Other())
+
+	var passed core.Bitboard
+	for b := pawns(p, c); b != 0; b &= b - 1 {
+		s := core.Square(bits.TrailingZeros64(uint64(b)))
+		span := (s.File().Bitboard() | core.AdjacentFiles(s.File())) & ranksAhead(c, s.Rank())
+		if span&theirs == 0 {
+			passed |= s.Bitboard()
+		}
+	}
+	return passed
+}
+
+// isProtected returns true if s is defended by a pawn of color c.
+func isProtected(p *core.Position, c core.Color, s core.Square) bool {
+	attacked := core.PawnSetAttacks(pawns(p, c), c)
+	return attacked.Get(s)
+}
+
+// isOutpost returns true if s is an outpost for color c: a square in the
+// other player's half of the board, defended by a pawn of color c, that no
+// pawn of the other player can ever attack.
+func isOutpost(p *core.Position, c core.Color, s core.Square) bool {
+	if r := relativeRank(c, s.Rank()); r < core.Rank4 || r > core.Rank6 {
+		return false
+	}
+	attackers := core.AdjacentFiles(s.File()) & ranksAhead(c, s.Rank())
+	return isProtected(p, c, s) && pawns(p, c.Other())&attackers == 0
+}
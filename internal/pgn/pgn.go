@@ -0,0 +1,278 @@
+// Package pgn reads games in Portable Game Notation.
+package pgn
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Game is a game read from PGN.
+type Game struct {
+	// The tag pairs, like "White" and "Event", by name.
+	Tags map[string]string
+
+	// The moves of the main line in Standard Algebraic Notation, like "e4" or
+	// "Nf3". Move numbers, comments, variations, and annotation glyphs are
+	// dropped.
+	Moves []string
+
+	// The game termination marker: "1-0", "0-1", "1/2-1/2", or "*" for a game
+	// in progress. It is empty if the movetext has no marker.
+	Result string
+}
+
+// Parse reads all games from r.
+//
+// Use a [Scanner] instead to read games one at a time without holding them
+// all in memory.
+func Parse(r io.Reader) ([]*Game, error) {
+	var games []*Game
+	s := NewScanner(r)
+	for s.Scan() {
+		games = append(games, s.Game())
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return games, nil
+}
+
+// A Scanner reads games from an input stream one at a time, like
+// [bufio.Scanner] reads lines.
+type Scanner struct {
+	s *bufio.Scanner
+
+	// The number of lines read so far.
+	line int
+
+	// A tag line read past the end of the previous game, or the rest of the
+	// line after its termination marker, which starts the next game.
+	pending string
+
+	// The most recent game.
+	game *Game
+
+	err error
+}
+
+// NewScanner returns a new [Scanner] that reads from r.
+func NewScanner(r io.Reader) *Scanner {
+	return &Scanner{s: bufio.NewScanner(r)}
+}
+
+// Scan advances to the next game, which is then available through
+// [Scanner.Game]. It returns false at the end of the input or on an error.
+//
+// A game ends at its termination marker, or else where the next game's tags
+// start.
+func (s *Scanner) Scan() bool {
+	if s.err != nil {
+		return false
+	}
+
+	g := &Game{Tags: make(map[string]string)}
+	var (
+		movetext strings.Builder
+		state    movetextState
+		started  bool
+	)
+
+	for {
+		text, ok := s.next()
+		if !ok {
+			break
+		}
+		text = strings.TrimSpace(text)
+
+		// Inside a comment, lines are movetext whatever they start with.
+		if !state.comment {
+			if strings.HasPrefix(text, "[") {
+				// A tag after movetext starts the next game.
+				if movetext.Len() > 0 {
+					s.pending = text
+					break
+				}
+				name, value, err := parseTag(text)
+				if err != nil {
+					s.err = fmt.Errorf("line %d: %w", s.line, err)
+					return false
+				}
+				g.Tags[name] = value
+				started = true
+				continue
+			}
+
+			// A line starting with "%" escapes the rest of the line.
+			if text == "" || strings.HasPrefix(text, "%") {
+				continue
+			}
+		}
+
+		started = true
+		if n, end := state.scan(text); end {
+			movetext.WriteString(text[:n])
+			s.pending = strings.TrimSpace(text[n:])
+			break
+		}
+		movetext.WriteString(text)
+		movetext.WriteByte('\n')
+	}
+
+	if err := s.s.Err(); err != nil {
+		s.err = fmt.Errorf("line %d: %w", s.line+1, err)
+		return false
+	}
+	if !started {
+		return false
+	}
+
+	g.Moves, g.Result = parseMovetext(movetext.String())
+	s.game = g
+	return true
+}
+
+// next returns the next line, either one held over from the previous game or
+// one read from the input.
+func (s *Scanner) next() (string, bool) {
+	if s.pending != "" {
+		text := s.pending
+		s.pending = ""
+		return text, true
+	}
+	if !s.s.Scan() {
+		return "", false
+	}
+	s.line++
+	return s.s.Text(), true
+}
+
+// Game returns the game read by the most recent call to [Scanner.Scan].
+func (s *Scanner) Game() *Game {
+	return s.game
+}
+
+// Err returns the first error that was encountered by the [Scanner].
+func (s *Scanner) Err() error {
+	return s.err
+}
+
+var (
+	regexpTag        = regexp.MustCompile(`^\[\s*(\w+)\s+"((?:[^"\\]|\\.)*)"\s*\]$`)
+	regexpMoveNumber = regexp.MustCompile(`^\d+\.+`)
+)
+
+// movetextState tracks the parts of movetext that span lines.
+type movetextState struct {
+	comment bool // Whether a {} comment is open.
+	depth   int  // The nesting depth of variations.
+}
+
+// scan reads a line of movetext. If the line holds the game termination
+// marker, it returns the index just past the marker and true.
+func (m *movetextState) scan(text string) (int, bool) {
+	start := -1 // The start of the current token, if any.
+	for i := 0; i <= len(text); i++ {
+		c := byte(' ')
+		if i < len(text) {
+			c = text[i]
+		}
+		if m.comment {
+			m.comment = c != '}'
+			continue
+		}
+		if !strings.ContainsRune(" \t{};()", rune(c)) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 && m.depth == 0 && isResult(text[start:i]) {
+			return i, true
+		}
+		start = -1
+
+		switch c {
+		case '{':
+			m.comment = true
+		case ';':
+			// The comment runs to the end of the line.
+			return 0, false
+		case '(':
+			m.depth++
+		case ')':
+			m.depth = max(m.depth-1, 0)
+		}
+	}
+	return 0, false
+}
+
+// isResult reports whether tok is a game termination marker.
+func isResult(tok string) bool {
+	switch tok {
+	case "1-0", "0-1", "1/2-1/2", "*":
+		return true
+	}
+	return false
+}
+
+// parseTag parses a tag pair, like `[White "Morphy, Paul"]`.
+func parseTag(text string) (name, value string, err error) {
+	subs := regexpTag.FindStringSubmatch(text)
+	if subs == nil {
+		return "", "", fmt.Errorf("invalid tag %q", text)
+	}
+	value = strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(subs[2])
+	return subs[1], value, nil
+}
+
+// parseMovetext returns the moves of the main line in text and its game
+// termination marker, if any.
+func parseMovetext(text string) (moves []string, result string) {
+	var (
+		b     strings.Builder
+		depth int // The nesting depth of variations.
+	)
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == '{':
+			// Comments don't nest.
+			if j := strings.IndexByte(text[i:], '}'); j >= 0 {
+				i += j
+			} else {
+				i = len(text)
+			}
+			b.WriteByte(' ')
+		case c == ';':
+			if j := strings.IndexByte(text[i:], '\n'); j >= 0 {
+				i += j
+			} else {
+				i = len(text)
+			}
+			b.WriteByte(' ')
+		case c == '(':
+			depth++
+			b.WriteByte(' ')
+		case c == ')':
+			depth = max(depth-1, 0)
+			b.WriteByte(' ')
+		case depth == 0:
+			b.WriteByte(c)
+		}
+	}
+
+	for _, tok := range strings.Fields(b.String()) {
+		if isResult(tok) {
+			result = tok
+			continue
+		}
+		// Drop move numbers like "1." and "1...", which may touch the move.
+		tok = regexpMoveNumber.ReplaceAllString(tok, "")
+		if tok != "" && !strings.HasPrefix(tok, "$") {
+			moves = append(moves, tok)
+		}
+	}
+	return moves, result
+}
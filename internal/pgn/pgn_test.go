@@ -0,0 +1,126 @@
+package pgn
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const games = `[Event "Paris"]
+[White "Morphy, Paul"]
+[Black "Duke Karl / Count Isouard"]
+[Result "1-0"]
+
+1. e4 e5 2. Nf3 d6 3. d4 Bg4 {This is a weak move.} 4. dxe5 Bxf3
+5. Qxf3 dxe5 6. Bc4 Nf6 7. Qb3 Qe7 8. Nc3 c6 9. Bg5 b5 10. Nxb5 cxb5
+11. Bxb5+ Nbd7 12. O-O-O Rd8 13. Rxd7 Rxd7 14. Rd1 Qe6 15. Bxd7+ Nxd7
+16. Qb8+ Nxb8 17. Rd8# 1-0
+
+[Event "Casual"]
+[White "A"]
+[Black "B"]
+
+1.e4 e5 2.Nf3 (2.f4 exf4 (2...d5) 3.Nf3) 2...Nc6 $1 ; a comment
+3.Bb5 a6 1/2-1/2
+
+[Event "Unfinished"]
+
+1. d4 d5 2. 0-0 *
+`
+
+func TestScanner(t *testing.T) {
+	want := []struct {
+		event  string
+		moves  int
+		last   string
+		result string
+	}{
+		{"Paris", 33, "Rd8#", "1-0"},
+		{"Casual", 6, "a6", "1/2-1/2"},
+		{"Unfinished", 3, "0-0", "*"},
+	}
+
+	s := NewScanner(strings.NewReader(games))
+	for i, w := range want {
+		if !s.Scan() {
+			t.Fatalf("Scan() for game %d: got false, err %v", i+1, s.Err())
+		}
+		g := s.Game()
+		if got := g.Tags["Event"]; got != w.event {
+			t.Errorf("game %d: got event %q, want %q", i+1, got, w.event)
+		}
+		if len(g.Moves) != w.moves || g.Moves[len(g.Moves)-1] != w.last {
+			t.Errorf("game %d: got moves %q, want %d ending in %q", i+1, g.Moves, w.moves, w.last)
+		}
+		if g.Result != w.result {
+			t.Errorf("game %d: got result %q, want %q", i+1, g.Result, w.result)
+		}
+	}
+
+	if s.Scan() {
+		t.Errorf("Scan() at EOF: got true, game %+v", s.Game())
+	}
+	if err := s.Err(); err != nil {
+		t.Errorf("Err(): %v", err)
+	}
+}
+
+func TestScanner_untagged(t *testing.T) {
+	const text = `1. e4 e5 2. Nf3 {A comment
+[that looks like a tag]
+} Nc6 1-0
+
+1. d4 d5 0-1 1. c4 *
+`
+	want := []*Game{
+		{Tags: map[string]string{}, Moves: []string{"e4", "e5", "Nf3", "Nc6"}, Result: "1-0"},
+		{Tags: map[string]string{}, Moves: []string{"d4", "d5"}, Result: "0-1"},
+		{Tags: map[string]string{}, Moves: []string{"c4"}, Result: "*"},
+	}
+
+	got, err := Parse(strings.NewReader(text))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse: got %d games %+v, want %+v", len(got), got, want)
+	}
+}
+
+func TestParse(t *testing.T) {
+	got, err := Parse(strings.NewReader(games))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("Parse: got %d games, want 3", len(got))
+	}
+
+	// Variations, comments, and annotation glyphs are dropped.
+	want := &Game{
+		Tags:   map[string]string{"Event": "Casual", "White": "A", "Black": "B"},
+		Moves:  []string{"e4", "e5", "Nf3", "Nc6", "Bb5", "a6"},
+		Result: "1/2-1/2",
+	}
+	if !reflect.DeepEqual(got[1], want) {
+		t.Errorf("Parse: got game %+v, want %+v", got[1], want)
+	}
+}
+
+func TestParse_error(t *testing.T) {
+	_, err := Parse(strings.NewReader("[Event \"x\"]\n[Bad tag]\n\n1. e4 *\n"))
+	if err == nil || !strings.HasPrefix(err.Error(), "line 2: ") {
+		t.Errorf("Parse: got error %v, want one for line 2", err)
+	}
+}
+
+func TestParseTag(t *testing.T) {
+	name, value, err := parseTag(`[Annotator "Said \"hi\" \\ bye"]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := []string{name, value}; !slices.Equal(got, []string{"Annotator", `Said "hi" \ bye`}) {
+		t.Errorf("parseTag: got %q", got)
+	}
+}
@@ -0,0 +1,50 @@
+package search
+
+import (
+	"context"
+	"errors"
+
+	"github.com/clfs/they/internal/core"
+)
+
+// ErrNoMoves is returned by [Analyze] for positions without legal moves.
+var ErrNoMoves = errors.New("no legal moves")
+
+// A Result is the outcome of analyzing a position.
+type Result struct {
+	// The best move.
+	Move core.Move
+
+	// The score of the best move in centipawns, from the perspective of the
+	// player whose turn it is.
+	Score int
+
+	// The principal variation: the best move and the expected replies, as far
+	// as they are known.
+	PV []core.Move
+
+	// Statistics about the search.
+	Stats Stats
+}
+
+// Analyze searches p synchronously with the given options and returns the
+// result. It is meant for analyzing many positions without the overhead of the
+// UCI protocol; passing the same [TT] in opts to each call reuses work between
+// related positions.
+//
+// Since Analyze cannot be canceled, opts.Depth or opts.Budget should be small
+// enough to finish in reasonable time. If p has no legal moves, Analyze returns
+// [ErrNoMoves].
+func Analyze(p core.Position, opts Options) (Result, error) {
+	if len(p.Moves()) == 0 {
+		return Result{}, ErrNoMoves
+	}
+
+	m, score, stats := Search(context.Background(), p, opts)
+	r := Result{Move: m, Score: score, Stats: stats, PV: []core.Move{m}}
+	if opts.TT != nil {
+		r.PV = opts.TT.PV(p, m, stats.Depth)
+	}
+
+	return r, nil
+}
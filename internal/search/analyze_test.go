@@ -0,0 +1,79 @@
+package search
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/clfs/they/internal/core"
+)
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		want core.Move
+	}{
+		{"back rank mate", "6k1/5ppp/8/8/8/8/5PPP/3R2K1 w - - 0 1", core.NewMove(core.D1, core.D8)},
+		{"hanging queen", "4k3/8/8/3q4/8/8/8/3RK3 w - - 0 1", core.NewMove(core.D1, core.D5)},
+		{"black mates", "3r2k1/8/8/8/8/8/5PPP/6K1 b - - 0 1", core.NewMove(core.D8, core.D1)},
+	}
+
+	tt := NewTT(1 << 16)
+	for _, test := range tests {
+		p, err := core.ParseFEN(test.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := Analyze(p, Options{Depth: 3, TT: tt})
+		if err != nil {
+			t.Errorf("%s: Analyze: %v", test.name, err)
+			continue
+		}
+		if r.Move != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, r.Move, test.want)
+		}
+		if len(r.PV) == 0 || r.PV[0] != r.Move {
+			t.Errorf("%s: got PV %v, want it to start with %v", test.name, r.PV, r.Move)
+		}
+
+		// The PV is a legal line.
+		q := p
+		for _, m := range r.PV {
+			if !q.IsLegal(m) {
+				t.Errorf("%s: PV %v has illegal move %v", test.name, r.PV, m)
+				break
+			}
+			q.Move(m)
+		}
+	}
+}
+
+func TestAnalyze_reusesTT(t *testing.T) {
+	p := playMoves(t, "e2e4 e7e5 g1f3 b8c6")
+	tt := NewTT(1 << 16)
+
+	first, err := Analyze(p, Options{Depth: 4, TT: tt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := Analyze(p, Options{Depth: 4, TT: tt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Stats.Nodes >= first.Stats.Nodes {
+		t.Errorf("second analysis searched %d nodes, want fewer than %d", second.Stats.Nodes, first.Stats.Nodes)
+	}
+	if len(second.PV) < 2 {
+		t.Errorf("got PV %v, want at least 2 moves", second.PV)
+	}
+}
+
+func TestAnalyze_noMoves(t *testing.T) {
+	p, err := core.ParseFEN("7k/5Q2/6K1/8/8/8/8/8 b - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Analyze(p, Options{Depth: 3}); !errors.Is(err, ErrNoMoves) {
+		t.Errorf("Analyze on stalemate: got %v, want %v", err, ErrNoMoves)
+	}
+}
@@ -0,0 +1,27 @@
+package search
+
+import "time"
+
+// A Clock tells the time. Searches use it to keep to their time budget, so
+// tests can control the time a search sees.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// After returns a channel that receives the current time once d has
+	// passed, like [time.After].
+	After(d time.Duration) <-chan time.Time
+}
+
+// systemClock is the [Clock] of the operating system.
+type systemClock struct{}
+
+// Now implements [Clock].
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// After implements [Clock].
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
@@ -0,0 +1,476 @@
+// Package search implements game tree search.
+package search
+
+import (
+	"context"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/clfs/they/internal/core"
+	"github.com/clfs/they/internal/eval"
+)
+
+// Score bounds, in centipawns.
+const (
+	// Infinity is greater than any score.
+	Infinity = 32000
+
+	// Mate is the score for delivering checkmate immediately. Checkmate n plies
+	// from the root scores Mate-n.
+	Mate = 31000
+)
+
+// MaxDepth is the maximum search depth in plies.
+const MaxDepth = 64
+
+// Options configure a search.
+type Options struct {
+	// The maximum search depth in plies. If zero or greater than MaxDepth,
+	// MaxDepth is used.
+	Depth int
+
+	// If positive, the best move is chosen at random among the root moves
+	// that score within this many centipawns of the best move. This gives
+	// variety in play, especially in the opening. Forced mates are never
+	// randomized.
+	Variety int
+
+	// The source of randomness for Variety. If nil, a randomly seeded source
+	// is used.
+	Rand *rand.Rand
+
+	// If not empty, only these moves are considered at the root. Illegal moves
+	// are ignored. If none are legal, all legal moves are considered.
+	SearchMoves []core.Move
+
+	// The hashes of the positions that led to p in the game, oldest first, as
+	// given by [core.Game.HistoryKeys]. Moves that repeat one of them score as
+	// draws.
+	History []core.Key
+
+	// The transposition table to use, if any. Passing the same table to later
+	// searches lets them reuse this search's results.
+	TT *TT
+
+	// How much the player to move at the root wants to avoid a draw, in
+	// centipawns. Draws score -Contempt for that player and Contempt for the
+	// other. A negative contempt makes the engine seek draws.
+	Contempt int
+
+	// The time to search for. If zero, there is no time limit. Like ctx being
+	// done, running out of time never stops the first iteration.
+	Budget time.Duration
+
+	// The clock that measures the search time. If nil, the system clock is
+	// used.
+	Clock Clock
+
+	// If not nil, Progress is called after each completed iteration, for
+	// reporting on long searches. It runs on the searching goroutine, so it
+	// should return quickly.
+	Progress func(Iteration)
+}
+
+// An Iteration is the result of one completed iteration of a search.
+type Iteration struct {
+	// The best move found so far.
+	Move core.Move
+
+	// Its score in centipawns, from the perspective of the player whose turn
+	// it is.
+	Score int
+
+	// Statistics about the search so far.
+	Stats Stats
+}
+
+// Stats describe the work done by a search.
+type Stats struct {
+	// The number of nodes searched.
+	Nodes int
+
+	// The depth of the deepest completed iteration, in plies.
+	Depth int
+
+	// The greatest distance from the root of any node searched, in plies.
+	SelDepth int
+
+	// The number of nodes found in the transposition table.
+	TTHits int
+
+	// The time spent searching.
+	Time time.Duration
+}
+
+// Search searches p with iterative deepening, using negamax with alpha-beta
+// pruning. It returns the best move, its score in centipawns from the
+// perspective of the player whose turn it is, and statistics about the search.
+//
+// If ctx is done or opts.Budget runs out before the search finishes, Search
+// returns the result of the deepest completed iteration. The first iteration
+// always completes.
+//
+// If p has no legal moves, Search returns the zero [core.Move] along with -Mate
+// for checkmate or -opts.Contempt for stalemate. If p is already drawn by
+// insufficient material or the 75-move rule, Search returns its first legal
+// move and -opts.Contempt without searching.
+func Search(ctx context.Context, p core.Position, opts Options) (core.Move, int, Stats) {
+	clock := opts.Clock
+	if clock == nil {
+		clock = systemClock{}
+	}
+	start := clock.Now()
+
+	s := searcher{
+		margin:   max(opts.Variety, 0),
+		tt:       opts.TT,
+		contempt: opts.Contempt,
+		history:  opts.History,
+	}
+
+	var timeout <-chan time.Time
+	if opts.Budget > 0 {
+		timeout = clock.After(opts.Budget)
+	}
+	if ctx.Done() != nil || timeout != nil {
+		// Raise the stop flag as soon as ctx is done or time runs out, so
+		// the search notices at its next node without reading the clock.
+		finished := make(chan struct{})
+		defer close(finished)
+		go func() {
+			select {
+			case <-ctx.Done():
+			case <-timeout:
+			case <-finished:
+				return
+			}
+			s.stop.Store(true)
+		}()
+	}
+
+	moves := p.Moves()
+	if len(moves) == 0 {
+		return core.Move{}, s.terminalScore(&p, 0), Stats{Nodes: 1, Time: clock.Now().Sub(start)}
+	}
+	if restricted := restrictMoves(moves, opts.SearchMoves); len(restricted) > 0 {
+		moves = restricted
+	}
+	orderMoves(&p, moves)
+	if s.isDrawn(&p) {
+		return moves[0], s.drawScore(0), Stats{Nodes: 1, Time: clock.Now().Sub(start)}
+	}
+
+	depth := opts.Depth
+	if depth <= 0 || depth > MaxDepth {
+		depth = MaxDepth
+	}
+
+	var (
+		best   core.Move
+		score  int
+		scores []int
+	)
+	for d := 1; d <= depth; d++ {
+		s.depth = d
+		m, v, vs := s.root(&p, moves, d)
+		if s.stopped {
+			break
+		}
+		best, score, scores = m, v, vs
+		s.stats.Depth = d
+		if opts.Progress != nil {
+			s.stats.Time = clock.Now().Sub(start)
+			opts.Progress(Iteration{Move: best, Score: score, Stats: s.stats})
+		}
+
+		// Search the best move first in the next iteration.
+		i := 0
+		for moves[i] != best {
+			i++
+		}
+		copy(moves[1:i+1], moves[:i])
+		moves[0] = best
+		copy(scores[1:i+1], scores[:i])
+		scores[0] = score
+	}
+
+	if s.margin > 0 && abs(score) < Mate-MaxDepth {
+		best = pickVaried(moves, scores, score-s.margin, opts.Rand)
+	}
+
+	s.stats.Time = clock.Now().Sub(start)
+	return best, score, s.stats
+}
+
+// restrictMoves returns the moves in moves that are also in allowed.
+func restrictMoves(moves, allowed []core.Move) []core.Move {
+	var restricted []core.Move
+	for _, m := range moves {
+		if slices.Contains(allowed, m) {
+			restricted = append(restricted, m)
+		}
+	}
+	return restricted
+}
+
+// pickVaried returns a random move among moves whose score is greater than
+// threshold.
+func pickVaried(moves []core.Move, scores []int, threshold int, r *rand.Rand) core.Move {
+	var candidates []core.Move
+	for i, m := range moves {
+		if scores[i] > threshold {
+			candidates = append(candidates, m)
+		}
+	}
+	if r == nil {
+		return candidates[rand.IntN(len(candidates))]
+	}
+	return candidates[r.IntN(len(candidates))]
+}
+
+// abs returns the absolute value of x.
+func abs(x int) int {
+	return max(x, -x)
+}
+
+// A searcher holds the state of a single search.
+type searcher struct {
+	// Raised when ctx is done or time runs out. It is the only field shared
+	// with another goroutine.
+	stop atomic.Bool
+
+	// The depth of the current iteration.
+	depth int
+
+	stats Stats
+
+	// Whether the search stopped early because ctx is done or time ran out.
+	stopped bool
+
+	// Root moves scoring within this many centipawns of the best move get
+	// exact scores.
+	margin int
+
+	// The transposition table, or nil if there is none.
+	tt *TT
+
+	// The contempt for draws of the player to move at the root.
+	contempt int
+
+	// The hashes of the positions before the root in the game.
+	history []core.Key
+
+	// The hashes of the positions from the start of the game to the current
+	// node, for detecting repetitions.
+	path []core.Key
+}
+
+// root searches the legal moves of p to the given depth and returns the best
+// move, its score, and the scores of all moves.
+//
+// The score of a move is exact if it is greater than the best score minus the
+// search margin. Otherwise, it is an upper bound.
+func (s *searcher) root(p *core.Position, moves []core.Move, depth int) (core.Move, int, []int) {
+	var (
+		best   core.Move
+		alpha  = -Infinity
+		scores = make([]int, len(moves))
+	)
+	s.path = append(append(s.path[:0], s.history...), p.Hash())
+	for i, m := range moves {
+		q := *p
+		q.Move(m)
+		lower := max(alpha-s.margin, -Infinity)
+		score := -s.negamax(&q, depth-1, 1, -Infinity, -lower)
+		if s.stopped {
+			break
+		}
+		scores[i] = score
+		if score > alpha {
+			alpha, best = score, m
+		}
+	}
+	return best, alpha, scores
+}
+
+// negamax returns the score of p searched to the given depth, ply plies from
+// the root.
+func (s *searcher) negamax(p *core.Position, depth, ply, alpha, beta int) int {
+	s.stats.Nodes++
+	s.stats.SelDepth = max(s.stats.SelDepth, ply)
+	if s.depth > 1 && s.stop.Load() {
+		s.stopped = true
+	}
+	if s.stopped {
+		return 0
+	}
+	if s.isDrawn(p) || s.isRepetition(p) {
+		return s.drawScore(ply)
+	}
+
+	buf := getMoves(p)
+	defer putMoves(buf)
+
+	moves := *buf
+	if len(moves) == 0 {
+		return s.terminalScore(p, ply)
+	}
+	if depth <= 0 {
+		return eval.Evaluate(p)
+	}
+
+	var (
+		key      core.Key
+		hashMove core.Move
+	)
+	if s.tt != nil {
+		key = p.Hash()
+		if e, ok := s.tt.Probe(key); ok {
+			s.stats.TTHits++
+			hashMove = e.Move
+			if score, ok := ttCutoff(e, depth, ply, alpha, beta); ok {
+				return score
+			}
+		}
+	}
+
+	orderMoves(p, moves)
+	if i := slices.Index(moves, hashMove); i > 0 {
+		copy(moves[1:i+1], moves[:i])
+		moves[0] = hashMove
+	}
+
+	var (
+		best  core.Move
+		bound = Upper
+	)
+	s.path = append(s.path, p.Hash())
+	defer func() { s.path = s.path[:len(s.path)-1] }()
+
+	for _, m := range moves {
+		q := *p
+		q.Move(m)
+		score := -s.negamax(&q, depth-1, ply+1, -beta, -alpha)
+		if score >= beta {
+			s.store(key, Entry{Move: m, Score: toTT(beta, ply), Depth: depth, Bound: Lower})
+			return beta
+		}
+		if score > alpha {
+			alpha, best, bound = score, m, Exact
+		}
+	}
+
+	s.store(key, Entry{Move: best, Score: toTT(alpha, ply), Depth: depth, Bound: bound})
+	return alpha
+}
+
+// isDrawn returns true if p is drawn by insufficient material or the 75-move
+// rule. Checkmate takes precedence over the 75-move rule, so positions in check
+// are left to the search.
+func (s *searcher) isDrawn(p *core.Position) bool {
+	if p.IsInsufficientMaterial() {
+		return true
+	}
+	return p.IsSeventyFiveMoveRule() && !p.InCheck()
+}
+
+// isRepetition returns true if p already occurred in the game or between the
+// root and the current node. The search treats the first repetition as a draw,
+// since the player who allowed it could repeat again.
+func (s *searcher) isRepetition(p *core.Position) bool {
+	// Only positions with the same player to move since the last capture or
+	// pawn move can repeat.
+	window := min(int(p.FiftyMoveRule), len(s.path))
+	key := p.Hash()
+	for i := 2; i <= window; i += 2 {
+		if s.path[len(s.path)-i] == key {
+			return true
+		}
+	}
+	return false
+}
+
+// ttCutoff returns the score of a node searched to the given depth, ply plies
+// from the root, with the window (alpha, beta), if e settles it.
+func ttCutoff(e Entry, depth, ply, alpha, beta int) (int, bool) {
+	if e.Depth < depth {
+		return 0, false
+	}
+	score := fromTT(e.Score, ply)
+	switch {
+	case e.Bound == Exact:
+		return min(max(score, alpha), beta), true
+	case e.Bound == Lower && score >= beta:
+		return beta, true
+	case e.Bound == Upper && score <= alpha:
+		return alpha, true
+	default:
+		return 0, false
+	}
+}
+
+// store stores e for the position with key k in the transposition table, if
+// there is one. Results of stopped searches are incomplete, so they are not
+// stored.
+func (s *searcher) store(k core.Key, e Entry) {
+	if s.tt == nil || s.stopped {
+		return
+	}
+	s.tt.Store(k, e)
+}
+
+// moveBuffers holds reusable move lists, so that searches don't allocate a
+// new one at every node. A pool keeps this safe for concurrent searches.
+var moveBuffers = sync.Pool{
+	New: func() any {
+		buf := make([]core.Move, 0, 256)
+		return &buf
+	},
+}
+
+// getMoves returns a buffer from moveBuffers holding the legal moves of p,
+// without underpromotions to a rook or bishop. Callers must return it with
+// putMoves when done.
+func getMoves(p *core.Position) *[]core.Move {
+	buf := moveBuffers.Get().(*[]core.Move)
+	*buf = p.AppendPlayMoves((*buf)[:0])
+	return buf
+}
+
+// putMoves resets buf and returns it to moveBuffers.
+func putMoves(buf *[]core.Move) {
+	*buf = (*buf)[:0]
+	moveBuffers.Put(buf)
+}
+
+// terminalScore returns the score of p, which has no legal moves, ply plies
+// from the root.
+func (s *searcher) terminalScore(p *core.Position, ply int) int {
+	if p.InCheck() {
+		return -Mate + ply
+	}
+	return s.drawScore(ply)
+}
+
+// drawScore returns the score of a draw ply plies from the root, from the
+// perspective of the player to move there.
+func (s *searcher) drawScore(ply int) int {
+	if ply%2 == 0 {
+		return -s.contempt
+	}
+	return s.contempt
+}
+
+// orderMoves sorts moves so that captures are searched first.
+func orderMoves(p *core.Position, moves []core.Move) {
+	i := 0
+	for j, m := range moves {
+		if !m.IsCastleEncoded() && p.Board.IsOccupied(m.To()) {
+			moves[i], moves[j] = moves[j], moves[i]
+			i++
+		}
+	}
+}
@@ -0,0 +1,374 @@
+package search
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/clfs/they/internal/core"
+)
+
+func TestSearch_matesWithQueen(t *testing.T) {
+	// The black king starts in the center, far from any mating net.
+	p, err := core.ParseFEN("8/8/8/3k4/8/8/8/Q3K3 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range 40 {
+		if len(p.Moves()) == 0 {
+			break
+		}
+		depth := 4
+		if p.Turn == core.Black {
+			depth = 2
+		}
+		m, _, _ := Search(context.Background(), p, Options{Depth: depth})
+		p.Move(m)
+	}
+
+	if len(p.Moves()) != 0 || !p.InCheck() {
+		t.Errorf("game did not end in checkmate")
+	}
+}
+
+func TestSearch_mateIn2(t *testing.T) {
+	p, _ := core.TestPosition("mate-in-2")
+	if _, score, _ := Search(context.Background(), p, Options{Depth: 3}); score != Mate-3 {
+		t.Errorf("got score %d, want %d", score, Mate-3)
+	}
+}
+
+func TestSearch_variety(t *testing.T) {
+	p := core.NewPosition()
+
+	search := func(opts Options) core.Move {
+		m, _, _ := Search(context.Background(), p, opts)
+		return m
+	}
+
+	t.Run("off", func(t *testing.T) {
+		want := search(Options{Depth: 2})
+		for range 5 {
+			if got := search(Options{Depth: 2}); got != want {
+				t.Fatalf("got %v, then %v", want, got)
+			}
+		}
+	})
+
+	t.Run("fixed seed", func(t *testing.T) {
+		opts := func() Options {
+			return Options{Depth: 2, Variety: 10, Rand: rand.New(rand.NewPCG(1, 2))}
+		}
+		want := search(opts())
+		for range 5 {
+			if got := search(opts()); got != want {
+				t.Fatalf("got %v, then %v", want, got)
+			}
+		}
+	})
+
+	t.Run("varies with seed", func(t *testing.T) {
+		seen := make(map[core.Move]bool)
+		for seed := range uint64(20) {
+			seen[search(Options{Depth: 2, Variety: 10, Rand: rand.New(rand.NewPCG(seed, seed))})] = true
+		}
+		if len(seen) < 2 {
+			t.Errorf("got %d distinct moves across seeds, want at least 2", len(seen))
+		}
+	})
+}
+
+func TestSearch_varietyKeepsMates(t *testing.T) {
+	p, err := core.ParseFEN("6k1/5ppp/8/8/8/8/5PPP/3R2K1 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for seed := range uint64(10) {
+		opts := Options{Depth: 3, Variety: 1000, Rand: rand.New(rand.NewPCG(seed, seed))}
+		if m, _, _ := Search(context.Background(), p, opts); m != core.NewMove(core.D1, core.D8) {
+			t.Errorf("seed %d: got %v, want d1d8", seed, m)
+		}
+	}
+}
+
+func TestSearch_stats(t *testing.T) {
+	_, _, stats := Search(context.Background(), core.NewPosition(), Options{Depth: 3})
+	if stats.Nodes == 0 {
+		t.Error("got 0 nodes")
+	}
+	if stats.Depth != 3 {
+		t.Errorf("got depth %d, want 3", stats.Depth)
+	}
+	if stats.SelDepth < stats.Depth {
+		t.Errorf("got seldepth %d, want at least %d", stats.SelDepth, stats.Depth)
+	}
+	if stats.TTHits != 0 {
+		t.Errorf("got %d TT hits without a TT, want 0", stats.TTHits)
+	}
+
+	// Iterative deepening revisits positions stored by earlier iterations.
+	_, _, stats = Search(context.Background(), core.NewPosition(), Options{Depth: 3, TT: NewTT(1 << 12)})
+	if stats.TTHits == 0 {
+		t.Error("got 0 TT hits with a TT")
+	}
+}
+
+func TestSearch_progress(t *testing.T) {
+	var its []Iteration
+	best, score, stats := Search(context.Background(), core.NewPosition(), Options{
+		Depth:    4,
+		Progress: func(it Iteration) { its = append(its, it) },
+	})
+
+	if len(its) != 4 {
+		t.Fatalf("got %d iterations, want 4", len(its))
+	}
+	for i, it := range its {
+		if it.Stats.Depth != i+1 {
+			t.Errorf("iteration %d: got depth %d, want %d", i, it.Stats.Depth, i+1)
+		}
+		if i > 0 && it.Stats.Nodes <= its[i-1].Stats.Nodes {
+			t.Errorf("iteration %d: got %d nodes, want more than %d", i, it.Stats.Nodes, its[i-1].Stats.Nodes)
+		}
+	}
+
+	// The last iteration is the result.
+	last := its[len(its)-1]
+	if last.Move != best || last.Score != score || last.Stats.Nodes != stats.Nodes {
+		t.Errorf("got last iteration %v, %d, %d nodes, want %v, %d, %d nodes",
+			last.Move, last.Score, last.Stats.Nodes, best, score, stats.Nodes)
+	}
+}
+
+func TestSearch_searchMoves(t *testing.T) {
+	// White mates with Rd8#.
+	p, err := core.ParseFEN("6k1/5ppp/8/8/8/8/5PPP/3R2K1 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		allowed []core.Move
+		want    core.Move
+	}{
+		{
+			"restricted",
+			[]core.Move{core.NewMove(core.G1, core.F1), core.NewMove(core.E1, core.E2)},
+			core.NewMove(core.G1, core.F1),
+		},
+		{
+			"none legal",
+			[]core.Move{core.NewMove(core.E1, core.E2)},
+			core.NewMove(core.D1, core.D8),
+		},
+	}
+	for _, test := range tests {
+		opts := Options{Depth: 3, SearchMoves: test.allowed}
+		if got, _, _ := Search(context.Background(), p, opts); got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestSearch_contempt(t *testing.T) {
+	tests := []struct {
+		name     string
+		fen      string
+		contempt int
+		want     int
+	}{
+		// Every move leaves too little material to mate.
+		{"insufficient material", "4k3/8/8/8/8/8/8/4KN2 w - - 0 1", 0, 0},
+		{"insufficient material with contempt", "4k3/8/8/8/8/8/8/4KN2 w - - 0 1", 50, -50},
+		{"insufficient material seeking draws", "4k3/8/8/8/8/8/8/4KN2 w - - 0 1", -50, 50},
+		{"stalemate with contempt", "7k/5Q2/6K1/8/8/8/8/8 b - - 0 1", 50, -50},
+	}
+	for _, test := range tests {
+		p, err := core.ParseFEN(test.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		opts := Options{Depth: 3, Contempt: test.contempt}
+		if _, got, _ := Search(context.Background(), p, opts); got != test.want {
+			t.Errorf("%s: got score %d, want %d", test.name, got, test.want)
+		}
+	}
+}
+
+func TestSearch_drawnPosition(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+	}{
+		{"kings only", "4k3/8/8/8/8/8/8/4K3 w - - 0 1"},
+		{"king and bishop", "4k3/8/8/8/8/8/8/2B1K3 b - - 0 1"},
+		{"75-move rule", "4k3/8/8/8/8/8/8/R3K3 w - - 150 100"},
+	}
+	for _, test := range tests {
+		p, err := core.ParseFEN(test.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, score, stats := Search(context.Background(), p, Options{Depth: 6})
+		if score != 0 {
+			t.Errorf("%s: got score %d, want 0", test.name, score)
+		}
+		if stats.Nodes != 1 {
+			t.Errorf("%s: got %d nodes, want 1", test.name, stats.Nodes)
+		}
+		if err := p.MoveError(m); err != nil {
+			t.Errorf("%s: got illegal move %v: %v", test.name, m, err)
+		}
+	}
+}
+
+func TestSearch_history(t *testing.T) {
+	// After the knights go out and White's comes back, Black's knight can
+	// return to reach the starting position again.
+	g := core.NewGame(core.NewPosition())
+	for _, m := range []core.Move{
+		core.NewMove(core.G1, core.F3),
+		core.NewMove(core.G8, core.F6),
+		core.NewMove(core.F3, core.G1),
+	} {
+		g.Move(m)
+	}
+	p := g.Position()
+	back := core.NewMove(core.F6, core.G8)
+
+	opts := Options{Depth: 2, Contempt: 50, SearchMoves: []core.Move{back}}
+	if _, score, _ := Search(context.Background(), p, opts); score == -50 {
+		t.Errorf("without history: got draw score %d", score)
+	}
+
+	opts.History = g.HistoryKeys()
+	if _, score, _ := Search(context.Background(), p, opts); score != -50 {
+		t.Errorf("with history: got score %d, want draw score -50", score)
+	}
+}
+
+func TestSearcher_isRepetition(t *testing.T) {
+	// Knights out and back reach the starting position again.
+	s := searcher{}
+	p := core.NewPosition()
+	for _, m := range []core.Move{
+		core.NewMove(core.G1, core.F3),
+		core.NewMove(core.G8, core.F6),
+		core.NewMove(core.F3, core.G1),
+		core.NewMove(core.F6, core.G8),
+	} {
+		if s.isRepetition(&p) {
+			t.Fatalf("repetition before %v", m)
+		}
+		s.path = append(s.path, p.Hash())
+		p.Move(m)
+	}
+	if !s.isRepetition(&p) {
+		t.Error("got no repetition after knights out and back")
+	}
+}
+
+// A fakeClock is a [Clock] whose time stands still until its timer fires,
+// which happens only when the test calls fire.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+
+	// The timer's channel and duration, from the last call to After.
+	after chan time.Time
+	d     time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.d = d
+	return c.after
+}
+
+// fire advances the clock to the timer's deadline, then fires the timer.
+func (c *fakeClock) fire() {
+	c.mu.Lock()
+	c.now = c.now.Add(c.d)
+	now := c.now
+	c.mu.Unlock()
+	c.after <- now
+}
+
+func TestSearch_budget(t *testing.T) {
+	const budget = 50 * time.Millisecond
+	clock := &fakeClock{now: time.Unix(0, 0), after: make(chan time.Time)}
+
+	done := make(chan Stats)
+	go func() {
+		// Without a depth limit, only the clock can stop the search.
+		_, _, stats := Search(context.Background(), core.NewPosition(), Options{
+			Budget: budget,
+			Clock:  clock,
+		})
+		done <- stats
+	}()
+
+	// Wait for the search to set its timer, then let the time pass.
+	for {
+		clock.mu.Lock()
+		d := clock.d
+		clock.mu.Unlock()
+		if d != 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	clock.fire()
+
+	select {
+	case stats := <-done:
+		if stats.Depth < 1 || stats.Depth >= MaxDepth {
+			t.Errorf("got depth %d, want the search stopped by the clock", stats.Depth)
+		}
+		// The search stops as soon as time runs out.
+		if stats.Time != budget {
+			t.Errorf("got time %v, want %v", stats.Time, budget)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("search did not stop when time ran out")
+	}
+}
+
+func TestSearch_concurrent(t *testing.T) {
+	p, err := core.ParseFEN("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := Options{Depth: 3}
+	wantMove, wantScore, _ := Search(context.Background(), p, opts)
+
+	// Searches share pooled move buffers, so they must not interfere.
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			m, score, _ := Search(context.Background(), p, opts)
+			if m != wantMove || score != wantScore {
+				t.Errorf("got %v with score %d, want %v with score %d", m, score, wantMove, wantScore)
+			}
+		})
+	}
+	wg.Wait()
+}
+
+func BenchmarkSearch(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		Search(context.Background(), core.NewPosition(), Options{Depth: 4})
+	}
+}
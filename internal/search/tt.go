@@ -0,0 +1,152 @@
+package search
+
+import (
+	"math/bits"
+
+	"github.com/clfs/they/internal/core"
+)
+
+// Bound describes how a stored score relates to the true score of a position.
+type Bound uint8
+
+// [Bound] constants.
+const (
+	// The score is exact.
+	Exact Bound = iota + 1
+
+	// The true score is at least the stored score.
+	Lower
+
+	// The true score is at most the stored score.
+	Upper
+)
+
+// An Entry is the result of searching a position, as stored in a [TT].
+type Entry struct {
+	// The best move found, or the zero [core.Move] if none is known.
+	Move core.Move
+
+	// The score, from the perspective of the player whose turn it is.
+	Score int
+
+	// The depth searched, in plies.
+	Depth int
+
+	// How Score relates to the true score.
+	Bound Bound
+}
+
+// ttSlot is an entry in a [TT] along with the key of its position.
+type ttSlot struct {
+	key   core.Key
+	entry Entry
+}
+
+// A TT is a transposition table: a cache of search results, indexed by
+// position. Reusing a TT across searches of related positions, like the moves
+// of one game, saves work.
+//
+// A TT must not be used by more than one search at a time.
+type TT struct {
+	slots []ttSlot
+}
+
+// NewTT returns a new [TT] with room for at least one and at most size
+// entries. The number of entries is rounded down to a power of two.
+func NewTT(size int) *TT {
+	n := 1
+	if size > 1 {
+		n = 1 << (bits.Len(uint(size)) - 1)
+	}
+	return &TT{slots: make([]ttSlot, n)}
+}
+
+// Len returns the number of entries the table has room for.
+func (t *TT) Len() int {
+	return len(t.slots)
+}
+
+// hashFullSample is the number of slots that [TT.HashFull] looks at.
+const hashFullSample = 1000
+
+// HashFull estimates how full the table is, in permille, from how many of its
+// first thousand slots are in use. Since positions map to slots uniformly, the
+// sample stands in for the whole table.
+func (t *TT) HashFull() int {
+	sample := t.slots[:min(len(t.slots), hashFullSample)]
+	used := 0
+	for i := range sample {
+		if sample[i].entry.Bound != 0 {
+			used++
+		}
+	}
+	return used * 1000 / len(sample)
+}
+
+// Probe returns the entry for the position with key k, if any.
+//
+// Each slot records the full key of its position, so a different position
+// that maps to the same slot is a miss rather than a wrong hit.
+func (t *TT) Probe(k core.Key) (Entry, bool) {
+	slot := &t.slots[k.Index(len(t.slots))]
+	if slot.entry.Bound == 0 || slot.key != k {
+		return Entry{}, false
+	}
+	return slot.entry, true
+}
+
+// Store stores the entry for the position with key k, replacing whatever
+// entry was in its slot.
+func (t *TT) Store(k core.Key, e Entry) {
+	t.slots[k.Index(len(t.slots))] = ttSlot{key: k, entry: e}
+}
+
+// PV returns the principal variation of p after a search: best, the best
+// move, followed by the best replies stored in t, at most n moves in all. The
+// line ends early at a position without an entry or whose stored move is not
+// legal, since another position may have replaced it.
+func (t *TT) PV(p core.Position, best core.Move, n int) []core.Move {
+	pv := []core.Move{best}
+	p.Move(best)
+	for len(pv) < n {
+		e, ok := t.Probe(p.Hash())
+		if !ok || !p.IsLegal(e.Move) {
+			break
+		}
+		pv = append(pv, e.Move)
+		p.Move(e.Move)
+	}
+	return pv
+}
+
+// Clear removes all entries.
+func (t *TT) Clear() {
+	clear(t.slots)
+}
+
+// toTT converts a score ply plies from the root to a score to store. Mate
+// scores are stored relative to the position rather than the root, so that
+// they stay correct when the position is reached at a different ply.
+func toTT(score, ply int) int {
+	switch {
+	case score > Mate-MaxDepth:
+		return score + ply
+	case score < -Mate+MaxDepth:
+		return score - ply
+	default:
+		return score
+	}
+}
+
+// fromTT converts a stored score to a score ply plies from the root. It undoes
+// toTT.
+func fromTT(score, ply int) int {
+	switch {
+	case score > Mate-MaxDepth:
+		return score - ply
+	case score < -Mate+MaxDepth:
+		return score + ply
+	default:
+		return score
+	}
+}
@@ -0,0 +1,149 @@
+package search
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/clfs/they/internal/core"
+)
+
+func TestNewTT(t *testing.T) {
+	tests := []struct {
+		size, want int
+	}{
+		{-1, 1},
+		{0, 1},
+		{1, 1},
+		{2, 2},
+		{1000, 512},
+		{1024, 1024},
+	}
+
+	for _, test := range tests {
+		if got := NewTT(test.size).Len(); got != test.want {
+			t.Errorf("NewTT(%d).Len(): got %d, want %d", test.size, got, test.want)
+		}
+	}
+}
+
+func TestTT_Probe(t *testing.T) {
+	a, b := core.NewPosition(), playMoves(t, "e2e4")
+	ka, kb := a.Hash(), b.Hash()
+	want := Entry{Move: core.NewMove(core.E2, core.E4), Score: 25, Depth: 3, Bound: Exact}
+
+	// With a single slot, every position maps to the same index.
+	tt := NewTT(1)
+
+	if _, ok := tt.Probe(ka); ok {
+		t.Error("Probe on an empty table: got a hit")
+	}
+
+	tt.Store(ka, want)
+	if got, ok := tt.Probe(ka); !ok || got != want {
+		t.Errorf("Probe after Store: got %v, %t, want %v, true", got, ok, want)
+	}
+	if got, ok := tt.Probe(kb); ok {
+		t.Errorf("Probe for another position in the same slot: got hit %v, want miss", got)
+	}
+
+	tt.Clear()
+	if _, ok := tt.Probe(ka); ok {
+		t.Error("Probe after Clear: got a hit")
+	}
+}
+
+func TestTT_HashFull(t *testing.T) {
+	tt := NewTT(1 << 10)
+	if got := tt.HashFull(); got != 0 {
+		t.Errorf("HashFull() of an empty table: got %d, want 0", got)
+	}
+
+	prev := 0
+	for depth := 2; depth <= 4; depth++ {
+		Search(context.Background(), core.NewPosition(), Options{Depth: depth, TT: tt})
+		got := tt.HashFull()
+		if got <= prev || got > 1000 {
+			t.Errorf("HashFull() after a depth %d search: got %d, want more than %d", depth, got, prev)
+		}
+		prev = got
+	}
+
+	tt.Clear()
+	if got := tt.HashFull(); got != 0 {
+		t.Errorf("HashFull() after Clear: got %d, want 0", got)
+	}
+}
+
+func TestTT_mateScores(t *testing.T) {
+	for _, score := range []int{Mate - 5, -Mate + 5, 0, 150, -150} {
+		stored := toTT(score, 3)
+		if got := fromTT(stored, 3); got != score {
+			t.Errorf("fromTT(toTT(%d, 3), 3): got %d", score, got)
+		}
+	}
+
+	// A mate found 5 plies from the root at ply 3 is 2 plies from the stored
+	// position, so it is 4 plies from the root when reached at ply 2.
+	if got, want := fromTT(toTT(Mate-5, 3), 2), Mate-4; got != want {
+		t.Errorf("mate score reached at a different ply: got %d, want %d", got, want)
+	}
+}
+
+func TestSearch_tt(t *testing.T) {
+	p := playMoves(t, "e2e4 e7e5 g1f3 b8c6")
+	tt := NewTT(1 << 16)
+
+	want, wantScore, without := Search(context.Background(), p, Options{Depth: 4})
+	first, firstScore, with := Search(context.Background(), p, Options{Depth: 4, TT: tt})
+
+	if first != want || firstScore != wantScore {
+		t.Errorf("with a TT: got %v with score %d, want %v with score %d", first, firstScore, want, wantScore)
+	}
+	if with.Nodes >= without.Nodes {
+		t.Errorf("with a TT: searched %d nodes, want fewer than %d", with.Nodes, without.Nodes)
+	}
+
+	// A second search reuses the first search's results.
+	second, secondScore, again := Search(context.Background(), p, Options{Depth: 4, TT: tt})
+	if second != want || secondScore != wantScore {
+		t.Errorf("with a reused TT: got %v with score %d, want %v with score %d", second, secondScore, want, wantScore)
+	}
+	if again.Nodes >= with.Nodes {
+		t.Errorf("with a reused TT: searched %d nodes, want fewer than %d", again.Nodes, with.Nodes)
+	}
+}
+
+// playMoves plays moves, given in UCI long algebraic notation separated by
+// spaces, from the starting position.
+func playMoves(t *testing.T, moves string) core.Position {
+	t.Helper()
+	p, _, err := core.Replay("startpos", strings.Fields(moves))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestTT_PV(t *testing.T) {
+	p := core.NewPosition()
+	tt := NewTT(1 << 16)
+	best, _, stats := Search(context.Background(), p, Options{Depth: 4, TT: tt})
+
+	pv := tt.PV(p, best, stats.Depth)
+	if len(pv) < 2 || len(pv) > stats.Depth || pv[0] != best {
+		t.Fatalf("got PV %v, want 2 to %d moves starting with %v", pv, stats.Depth, best)
+	}
+	q := p
+	for _, m := range pv {
+		if !q.IsLegal(m) {
+			t.Fatalf("PV %v has illegal move %v", pv, m)
+		}
+		q.Move(m)
+	}
+
+	// An empty table knows only the best move.
+	if got := NewTT(16).PV(p, best, 4); len(got) != 1 || got[0] != best {
+		t.Errorf("empty table: got PV %v, want [%v]", got, best)
+	}
+}
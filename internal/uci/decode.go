@@ -0,0 +1,242 @@
+package uci
+
+import (
+	"bufio"
+	"bytes"
+	"encoding"
+	"fmt"
+	"io"
+)
+
+// Message is a UCI message.
+type Message interface {
+	encoding.TextUnmarshaler
+	encoding.TextAppender
+}
+
+// Blank represents a blank line.
+type Blank struct{}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (m *Blank) UnmarshalText(text []byte) error {
+	if len(bytes.TrimSpace(text)) != 0 {
+		return parseError("blank", "", ErrWrongCommand)
+	}
+	return nil
+}
+
+// AppendText implements [encoding.TextAppender].
+func (m *Blank) AppendText(b []byte) ([]byte, error) {
+	return b, nil
+}
+
+// Unknown represents a line that is not a valid UCI message.
+//
+// Per the UCI protocol, unknown messages should be ignored.
+type Unknown struct {
+	Text string
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (m *Unknown) UnmarshalText(text []byte) error {
+	m.Text = string(text)
+	return nil
+}
+
+// AppendText implements [encoding.TextAppender].
+func (m *Unknown) AppendText(b []byte) ([]byte, error) {
+	return append(b, m.Text...), nil
+}
+
+// A Direction is the direction of the messages a [Decoder] reads.
+type Direction int
+
+// [Direction] constants.
+const (
+	// AnyInput accepts messages in either direction. It is the default.
+	AnyInput Direction = iota
+
+	// EngineInput accepts only messages that a GUI sends to an engine, like
+	// "go".
+	EngineInput
+
+	// GUIInput accepts only messages that an engine sends to a GUI, like
+	// "bestmove", as well as [PerftMove] and [PerftTotal] lines.
+	GUIInput
+)
+
+// A Decoder reads UCI messages from an input stream.
+type Decoder struct {
+	s *bufio.Scanner
+
+	// The number of lines read so far.
+	line int
+
+	// The direction of the messages to accept.
+	dir Direction
+
+	// Why the most recently read message is an [*Unknown], if it is one.
+	parseErr error
+}
+
+// NewDecoder returns a new [Decoder] that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{s: bufio.NewScanner(r)}
+}
+
+// Buffer sets the initial buffer to use when reading lines and the maximum
+// length of a line, like [bufio.Scanner.Buffer]. By default, lines may be up
+// to [bufio.MaxScanTokenSize] bytes long, which fits a "position" command with
+// thousands of moves.
+//
+// Buffer panics if it is called after reading starts.
+func (d *Decoder) Buffer(buf []byte, max int) {
+	d.s.Buffer(buf, max)
+}
+
+// SetDirection sets the direction of the messages to accept. Lines that are
+// messages in the other direction are returned as [*Unknown], as the UCI
+// protocol requires for commands an engine or GUI doesn't recognize.
+func (d *Decoder) SetDirection(dir Direction) {
+	d.dir = dir
+}
+
+// ReadMessage reads the next message.
+//
+// Lines that are not valid UCI messages are returned as [*Unknown], and
+// [Decoder.ParseErr] reports why. At the end of the input stream, ReadMessage
+// returns [io.EOF]. Other errors include the number of the line that could not
+// be read.
+func (d *Decoder) ReadMessage() (Message, error) {
+	d.parseErr = nil
+	if !d.s.Scan() {
+		if err := d.s.Err(); err != nil {
+			return nil, fmt.Errorf("line %d: %w", d.line+1, err)
+		}
+		return nil, io.EOF
+	}
+	d.line++
+
+	text := bytes.TrimSpace(d.s.Bytes())
+
+	m, err := parse(text, d.dir)
+	if err != nil {
+		if pe, ok := err.(*ParseError); ok {
+			pe.Line = d.line
+		}
+		d.parseErr = err
+		return &Unknown{Text: string(text)}, nil
+	}
+	return m, nil
+}
+
+// ParseErr returns the reason the most recently read message is an
+// [*Unknown], or nil if it isn't one. The error is a [*ParseError] with its
+// Line set, like "line 3: uci: go command: invalid argument "x"".
+func (d *Decoder) ParseErr() error {
+	return d.parseErr
+}
+
+// Parse parses a single message from text, which must not include the
+// trailing newline. The type of the message depends on the first token of
+// text.
+//
+// Unlike [Decoder.ReadMessage], Parse returns an error for invalid text,
+// including a [*ParseError] wrapping [ErrUnknownCommand] if the first token
+// isn't a known command.
+func Parse(text []byte) (Message, error) {
+	return parse(text, AnyInput)
+}
+
+// parse is like [Parse], but it only accepts messages in the given direction.
+func parse(text []byte, dir Direction) (Message, error) {
+	m := newMessage(text, dir)
+	if _, ok := m.(*Unknown); ok {
+		first, _, _ := bytes.Cut(text, []byte(" "))
+		return nil, parseError(string(first), "", ErrUnknownCommand)
+	}
+	if err := m.UnmarshalText(text); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Line returns the line number of the most recently read message, starting
+// from 1. Before the first call to [Decoder.ReadMessage], it returns 0.
+//
+// Line is useful for reporting where a message came from, like an [*Unknown]
+// message in a long script of commands.
+func (d *Decoder) Line() int {
+	return d.line
+}
+
+// newMessage returns a new message of the type indicated by text, if it is a
+// message in the given direction.
+func newMessage(text []byte, dir Direction) Message {
+	m := commandMessage(text)
+	if md := messageDirection(m); dir != AnyInput && md != AnyInput && md != dir {
+		return new(Unknown)
+	}
+
+	// Perft output has no command token of its own.
+	if _, ok := m.(*Unknown); ok && dir == GUIInput {
+		if regexpPerftTotal.Match(text) {
+			return new(PerftTotal)
+		}
+		return new(PerftMove)
+	}
+	return m
+}
+
+// messageDirection returns the direction m is sent in, or [AnyInput] if it
+// may be sent either way.
+func messageDirection(m Message) Direction {
+	switch m.(type) {
+	case *UCI, *IsReady, *SetOption, *Register, *UCINewGame, *Position, *Go, *Stop, *Quit:
+		return EngineInput
+	case *ID, *UCIOk, *ReadyOk, *BestMove, *Info, *PerftMove, *PerftTotal:
+		return GUIInput
+	default:
+		return AnyInput
+	}
+}
+
+// commandMessage returns a new message of the type indicated by the first
+// token of text.
+func commandMessage(text []byte) Message {
+	first, _, _ := bytes.Cut(text, []byte(" "))
+	switch string(first) {
+	case "":
+		return new(Blank)
+	case "uci":
+		return new(UCI)
+	case "isready":
+		return new(IsReady)
+	case "setoption":
+		return new(SetOption)
+	case "register":
+		return new(Register)
+	case "ucinewgame":
+		return new(UCINewGame)
+	case "position":
+		return new(Position)
+	case "go":
+		return new(Go)
+	case "stop":
+		return new(Stop)
+	case "quit":
+		return new(Quit)
+	case "id":
+		return new(ID)
+	case "uciok":
+		return new(UCIOk)
+	case "readyok":
+		return new(ReadyOk)
+	case "bestmove":
+		return new(BestMove)
+	case "info":
+		return new(Info)
+	default:
+		return new(Unknown)
+	}
+}
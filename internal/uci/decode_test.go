@@ -0,0 +1,266 @@
+package uci
+
+import (
+	"bufio"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDecoder_Line(t *testing.T) {
+	d := NewDecoder(strings.NewReader("uci\nisready\nbogus command\nquit\n"))
+	if got := d.Line(); got != 0 {
+		t.Errorf("Line() before reading: got %d, want 0", got)
+	}
+
+	for want := 1; want <= 3; want++ {
+		msg, err := d.ReadMessage()
+		if err != nil {
+			t.Fatalf("ReadMessage(): %v", err)
+		}
+		if got := d.Line(); got != want {
+			t.Errorf("Line() after %T: got %d, want %d", msg, got, want)
+		}
+		if _, unknown := msg.(*Unknown); unknown != (want == 3) {
+			t.Errorf("line %d: got %T", want, msg)
+		}
+	}
+}
+
+func TestDecoder_ReadMessage_lineInError(t *testing.T) {
+	long := strings.Repeat("x", bufio.MaxScanTokenSize)
+	d := NewDecoder(strings.NewReader("uci\nisready\n" + long + "\n"))
+
+	for range 2 {
+		if _, err := d.ReadMessage(); err != nil {
+			t.Fatalf("ReadMessage(): %v", err)
+		}
+	}
+	_, err := d.ReadMessage()
+	if err == nil || !strings.HasPrefix(err.Error(), "line 3: ") {
+		t.Errorf("ReadMessage(): got error %v, want one for line 3", err)
+	}
+}
+
+func TestDecoder_ParseErr(t *testing.T) {
+	d := NewDecoder(strings.NewReader("uci\nisready\ngo depth x\nquit\n"))
+
+	for range 2 {
+		if _, err := d.ReadMessage(); err != nil {
+			t.Fatalf("ReadMessage(): %v", err)
+		}
+		if err := d.ParseErr(); err != nil {
+			t.Errorf("ParseErr() on line %d: got %v, want nil", d.Line(), err)
+		}
+	}
+
+	msg, err := d.ReadMessage()
+	if err != nil {
+		t.Fatalf("ReadMessage(): %v", err)
+	}
+	if _, ok := msg.(*Unknown); !ok {
+		t.Errorf("ReadMessage(): got %T, want *Unknown", msg)
+	}
+	err = d.ParseErr()
+	var pe *ParseError
+	if !errors.As(err, &pe) || pe.Line != 3 || !errors.Is(err, ErrInvalidArg) {
+		t.Errorf("ParseErr(): got %#v, want a *ParseError for line 3", err)
+	}
+	if err == nil || !strings.HasPrefix(err.Error(), "line 3: ") {
+		t.Errorf("ParseErr(): got error %v, want one starting with \"line 3: \"", err)
+	}
+
+	if _, err := d.ReadMessage(); err != nil {
+		t.Fatalf("ReadMessage(): %v", err)
+	}
+	if err := d.ParseErr(); err != nil {
+		t.Errorf("ParseErr() on line 4: got %v, want nil", err)
+	}
+}
+
+func TestDecoder_ReadMessage_longPosition(t *testing.T) {
+	moves := strings.Repeat(" g1f3 g8f6 f3g1 f6g8", 75)
+	d := NewDecoder(strings.NewReader("position startpos moves" + moves + "\n"))
+
+	msg, err := d.ReadMessage()
+	if err != nil {
+		t.Fatalf("ReadMessage(): %v", err)
+	}
+	pos, ok := msg.(*Position)
+	if !ok {
+		t.Fatalf("ReadMessage(): got %T, want *Position", msg)
+	}
+	if got, want := pos.Moves, strings.Fields(moves); !slices.Equal(got, want) {
+		t.Errorf("ReadMessage(): got %d moves, want %d", len(got), len(want))
+	}
+}
+
+func TestDecoder_Buffer(t *testing.T) {
+	d := NewDecoder(strings.NewReader("position startpos moves e2e4 e7e5\n"))
+	d.Buffer(nil, 16)
+
+	if _, err := d.ReadMessage(); err == nil {
+		t.Error("ReadMessage(): got nil error for a line longer than the buffer")
+	}
+}
+
+func TestDecoder_ReadMessage_position(t *testing.T) {
+	const fen = "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1"
+
+	tests := []struct {
+		name string
+		text string
+		want Message
+	}{
+		{
+			"startpos with moves",
+			"position startpos moves e2e4 e7e5",
+			&Position{Startpos: true, Moves: []string{"e2e4", "e7e5"}},
+		},
+		{
+			"fen",
+			"position fen " + fen,
+			&Position{FEN: fen},
+		},
+		{
+			"fen with moves",
+			"position fen " + fen + " moves e7e5 g1f3",
+			&Position{FEN: fen, Moves: []string{"e7e5", "g1f3"}},
+		},
+		{
+			"startpos and fen",
+			"position startpos fen " + fen,
+			&Unknown{Text: "position startpos fen " + fen},
+		},
+		{
+			"fen and startpos",
+			"position fen startpos",
+			&Unknown{Text: "position fen startpos"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d := NewDecoder(strings.NewReader(test.text + "\n"))
+			got, err := d.ReadMessage()
+			if err != nil {
+				t.Fatalf("ReadMessage(): %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("ReadMessage(): got %#v, want %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    Message
+		wantErr bool
+	}{
+		{text: "", want: &Blank{}},
+		{text: "uci", want: &UCI{}},
+		{text: "isready", want: &IsReady{}},
+		{text: "quit", want: &Quit{}},
+		{text: "position startpos moves e2e4", want: &Position{Startpos: true, Moves: []string{"e2e4"}}},
+		{text: "go depth 3", want: &Go{Depth: 3}},
+		{text: "bestmove e2e4 ponder e7e5", want: &BestMove{Move: "e2e4", Ponder: "e7e5"}},
+		{text: "setoption name Hash value 32", want: &SetOption{Name: "Hash", Value: "32"}},
+		{text: "register name Stefan MK code 4359874324", want: &Register{Name: "Stefan MK", Code: "4359874324"}},
+		{text: "hello world", wantErr: true},
+		{text: "position", wantErr: true},
+		{text: "go depth x", wantErr: true},
+		{text: "bestmove", wantErr: true},
+	}
+
+	for _, test := range tests {
+		got, err := Parse([]byte(test.text))
+		if gotErr := err != nil; gotErr != test.wantErr {
+			t.Errorf("Parse(%q): gotErr %v, wantErr %v", test.text, gotErr, test.wantErr)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Parse(%q): got %#v, want %#v", test.text, got, test.want)
+		}
+	}
+}
+
+func TestParse_errors(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    error
+		command string
+	}{
+		{"hello world", ErrUnknownCommand, "hello"},
+		{"setoption", ErrInvalidArg, "setoption"},
+		{"setoption value 32", ErrInvalidArg, "setoption"},
+		{"go depth", ErrMissingArg, "go"},
+		{"go depth x", ErrInvalidArg, "go"},
+		{"go sideways", ErrUnexpectedToken, "go"},
+		{"position startpos fen 8/8/8/8/8/8/8/8 w - - 0 1", ErrConflictingArgs, "position"},
+		{"position fen", ErrMissingArg, "position"},
+		{"info score cp", ErrMissingArg, "info"},
+		{"bestmove", ErrInvalidArg, "bestmove"},
+	}
+
+	for _, test := range tests {
+		_, err := Parse([]byte(test.text))
+		if !errors.Is(err, test.want) {
+			t.Errorf("Parse(%q): got error %v, want %v", test.text, err, test.want)
+		}
+		var pe *ParseError
+		if !errors.As(err, &pe) || pe.Command != test.command {
+			t.Errorf("Parse(%q): got error %#v, want a *ParseError for %q", test.text, err, test.command)
+		}
+	}
+}
+
+func TestMessage_UnmarshalText_wrongCommand(t *testing.T) {
+	msgs := []Message{&UCI{}, &IsReady{}, &Go{}, &Info{}, &Blank{}}
+	for _, m := range msgs {
+		if err := m.UnmarshalText([]byte("ucinewgame now")); !errors.Is(err, ErrWrongCommand) {
+			t.Errorf("%T.UnmarshalText: got error %v, want %v", m, err, ErrWrongCommand)
+		}
+	}
+}
+
+func TestDecoder_SetDirection(t *testing.T) {
+	tests := []struct {
+		text string
+		dir  Direction
+		want Message
+	}{
+		{"go depth 1", AnyInput, &Go{Depth: 1}},
+		{"go depth 1", EngineInput, &Go{Depth: 1}},
+		{"go depth 1", GUIInput, &Unknown{Text: "go depth 1"}},
+		{"info depth 1", AnyInput, &Info{Depth: 1}},
+		{"info depth 1", EngineInput, &Unknown{Text: "info depth 1"}},
+		{"info depth 1", GUIInput, &Info{Depth: 1}},
+		{
+			"info depth 10 multipv 1 score cp 20 pv e2e4",
+			GUIInput,
+			&Info{Depth: 10, MultiPV: 1, Score: &Score{CP: 20}, PV: []string{"e2e4"}},
+		},
+		{"e2e4: 20", AnyInput, &Unknown{Text: "e2e4: 20"}},
+		{"e2e4: 20", EngineInput, &Unknown{Text: "e2e4: 20"}},
+		{"e2e4: 20", GUIInput, &PerftMove{Move: "e2e4", Nodes: 20}},
+		{"Nodes searched: 400", GUIInput, &PerftTotal{Nodes: 400}},
+		{"hello world", GUIInput, &Unknown{Text: "hello world"}},
+		{"", EngineInput, &Blank{}},
+		{"", GUIInput, &Blank{}},
+	}
+
+	for _, test := range tests {
+		d := NewDecoder(strings.NewReader(test.text + "\n"))
+		d.SetDirection(test.dir)
+		got, err := d.ReadMessage()
+		if err != nil {
+			t.Fatalf("ReadMessage(): %v", err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ReadMessage() of %q with direction %d: got %#v, want %#v", test.text, test.dir, got, test.want)
+		}
+	}
+}
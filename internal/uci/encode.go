@@ -0,0 +1,35 @@
+package uci
+
+import (
+	"io"
+	"sync"
+)
+
+// An Encoder writes UCI messages to an output stream.
+//
+// It is safe to call WriteMessage from multiple goroutines.
+type Encoder struct {
+	mu  sync.Mutex
+	w   io.Writer
+	buf []byte
+}
+
+// NewEncoder returns a new [Encoder] that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// WriteMessage writes m followed by a newline.
+func (e *Encoder) WriteMessage(m Message) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	b, err := m.AppendText(e.buf[:0])
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	e.buf = b
+	_, err = e.w.Write(b)
+	return err
+}
@@ -0,0 +1,68 @@
+package uci
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors wrapped by a [*ParseError] or returned when encoding a message, for
+// use with [errors.Is].
+var (
+	// ErrUnknownCommand means text doesn't start with a known command.
+	ErrUnknownCommand = errors.New("unknown command")
+
+	// ErrWrongCommand means text is a different command than the message
+	// being unmarshaled.
+	ErrWrongCommand = errors.New("wrong command")
+
+	// ErrMissingArg means a command lacks a required argument.
+	ErrMissingArg = errors.New("missing argument")
+
+	// ErrInvalidArg means an argument has an invalid value.
+	ErrInvalidArg = errors.New("invalid argument")
+
+	// ErrUnexpectedToken means a command has a token it doesn't accept.
+	ErrUnexpectedToken = errors.New("unexpected token")
+
+	// ErrConflictingArgs means a command has arguments that exclude each
+	// other, like both "startpos" and "fen".
+	ErrConflictingArgs = errors.New("conflicting arguments")
+)
+
+// A ParseError describes text that could not be unmarshaled into a message.
+type ParseError struct {
+	// The command being parsed, like "go".
+	Command string
+
+	// The token at fault, if any.
+	Token string
+
+	// The cause, like [ErrMissingArg].
+	Err error
+
+	// The number of the line the text was read from, starting from 1, if it
+	// was read by a [Decoder]. Otherwise, 0.
+	Line int
+}
+
+// Error implements the error interface.
+func (e *ParseError) Error() string {
+	s := fmt.Sprintf("uci: %s command: %v", e.Command, e.Err)
+	if e.Token != "" {
+		s += fmt.Sprintf(" %q", e.Token)
+	}
+	if e.Line > 0 {
+		s = fmt.Sprintf("line %d: %s", e.Line, s)
+	}
+	return s
+}
+
+// Unwrap returns the cause of e.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// parseError returns a [*ParseError] for the given command.
+func parseError(command, token string, err error) error {
+	return &ParseError{Command: command, Token: token, Err: err}
+}
@@ -0,0 +1,23 @@
+package uci_test
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/clfs/they/internal/uci"
+)
+
+func ExampleParse() {
+	msg, err := uci.Parse([]byte("go wtime 60000 btime 60000 movestogo 40"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch msg := msg.(type) {
+	case *uci.Go:
+		fmt.Println(msg.WTime, msg.BTime, msg.MovesToGo)
+	default:
+		fmt.Printf("unexpected %T\n", msg)
+	}
+	// Output: 1m0s 1m0s 40
+}
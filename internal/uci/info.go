@@ -0,0 +1,72 @@
+package uci
+
+import "time"
+
+// An InfoOption sets a field of an [Info]. See [NewInfo].
+type InfoOption func(*Info)
+
+// NewInfo returns an [Info] with the given options applied in order.
+//
+// It is an alternative to an Info literal that reads well when most fields
+// are absent, like:
+//
+//	NewInfo(WithDepth(10), WithScoreCP(34), WithPV("e2e4", "e7e5"))
+func NewInfo(opts ...InfoOption) *Info {
+	m := new(Info)
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// WithDepth sets the search depth in plies.
+func WithDepth(depth int) InfoOption {
+	return func(m *Info) { m.Depth = depth }
+}
+
+// WithSelDepth sets the selective search depth in plies.
+func WithSelDepth(depth int) InfoOption {
+	return func(m *Info) { m.SelDepth = depth }
+}
+
+// WithTime sets the time searched.
+func WithTime(d time.Duration) InfoOption {
+	return func(m *Info) { m.Time = d }
+}
+
+// WithNodes sets the number of nodes searched.
+func WithNodes(n int) InfoOption {
+	return func(m *Info) { m.Nodes = n }
+}
+
+// WithNPS sets the number of nodes searched per second.
+func WithNPS(n int) InfoOption {
+	return func(m *Info) { m.NPS = n }
+}
+
+// WithHashFull sets the permille of the hash table in use.
+func WithHashFull(permille int) InfoOption {
+	return func(m *Info) { m.HashFull = permille }
+}
+
+// WithPV sets the principal variation, in long algebraic notation.
+func WithPV(moves ...string) InfoOption {
+	return func(m *Info) { m.PV = moves }
+}
+
+// WithScoreCP sets the score in centipawns. It replaces any earlier score,
+// including a mate score.
+func WithScoreCP(cp int) InfoOption {
+	return func(m *Info) { m.Score = &Score{CP: cp} }
+}
+
+// WithScoreMate sets the score to a mate in n moves, or to getting mated in -n
+// moves if n is negative. It replaces any earlier score.
+func WithScoreMate(n int) InfoOption {
+	return func(m *Info) { m.Score = &Score{Mate: n} }
+}
+
+// WithString sets the string to display.
+func WithString(s string) InfoOption {
+	return func(m *Info) { m.Str = s }
+}
@@ -0,0 +1,55 @@
+package uci
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewInfo(t *testing.T) {
+	tests := []struct {
+		name string
+		got  *Info
+		want *Info
+	}{
+		{"empty", NewInfo(), &Info{}},
+		{
+			"search",
+			NewInfo(
+				WithDepth(10),
+				WithSelDepth(14),
+				WithScoreCP(34),
+				WithNodes(123456),
+				WithNPS(1000000),
+				WithTime(1500*time.Millisecond),
+				WithHashFull(250),
+				WithPV("e2e4", "e7e5"),
+			),
+			&Info{
+				Depth:    10,
+				SelDepth: 14,
+				Score:    &Score{CP: 34},
+				Nodes:    123456,
+				NPS:      1000000,
+				Time:     1500 * time.Millisecond,
+				HashFull: 250,
+				PV:       []string{"e2e4", "e7e5"},
+			},
+		},
+		{"mate", NewInfo(WithScoreMate(-3)), &Info{Score: &Score{Mate: -3}}},
+		{"later score wins", NewInfo(WithScoreMate(2), WithScoreCP(50)), &Info{Score: &Score{CP: 50}}},
+		{"string", NewInfo(WithScoreCP(0), WithString("draw")), &Info{Score: &Score{CP: 0}, Str: "draw"}},
+	}
+
+	for _, test := range tests {
+		if !reflect.DeepEqual(test.got, test.want) {
+			t.Errorf("%s: got %#v, want %#v", test.name, test.got, test.want)
+		}
+		// Both forms encode the same way, or fail to.
+		got, gotErr := test.got.AppendText(nil)
+		want, wantErr := test.want.AppendText(nil)
+		if string(got) != string(want) || (gotErr == nil) != (wantErr == nil) {
+			t.Errorf("%s: AppendText: got %q, %v, want %q, %v", test.name, got, gotErr, want, wantErr)
+		}
+	}
+}
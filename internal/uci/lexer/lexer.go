@@ -0,0 +1,335 @@
+// Package lexer splits lines of UCI text into tokens.
+package lexer
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// TokenType is the type of a [Token].
+type TokenType int
+
+// [TokenType] constants.
+const (
+	// The end of the input. It is always the last token.
+	EOF TokenType = iota + 1
+
+	// The newline ending a line.
+	EOL
+
+	// A word that is a decimal integer, like "20" or "-35".
+	LiteralNumber
+
+	// A word that isn't a keyword or number in its context, like "e2e4", or
+	// any word of free text, like the engine name after "id name".
+	LiteralString
+
+	// Commands from the GUI to the engine.
+	UCI
+	Debug
+	IsReady
+	SetOption
+	Register
+	UCINewGame
+	Position
+	Go
+	Stop
+	PonderHit
+	Quit
+
+	// Commands from the engine to the GUI.
+	ID
+	UCIOk
+	ReadyOk
+	BestMove
+	CopyProtection
+	Registration
+	Info
+	Option
+
+	// Arguments of commands.
+	On
+	Off
+	Name
+	Value
+	Author
+	Later
+	Code
+	Startpos
+	FEN
+	Moves
+	SearchMoves
+	Ponder
+	WTime
+	BTime
+	WInc
+	BInc
+	MovesToGo
+	Depth
+	Nodes
+	Mate
+	MoveTime
+	Infinite
+	Checking
+	Ok
+	Error
+	SelDepth
+	Time
+	PV
+	MultiPV
+	Score
+	CP
+	LowerBound
+	UpperBound
+	CurrMove
+	CurrMoveNumber
+	HashFull
+	NPS
+	TBHits
+	SBHits
+	CPULoad
+	String
+	Refutation
+	CurrLine
+	Type
+	Default
+	Min
+	Max
+	Var
+)
+
+// keywords maps the text of each keyword to its [TokenType].
+var keywords = map[string]TokenType{
+	"uci":            UCI,
+	"debug":          Debug,
+	"isready":        IsReady,
+	"setoption":      SetOption,
+	"register":       Register,
+	"ucinewgame":     UCINewGame,
+	"position":       Position,
+	"go":             Go,
+	"stop":           Stop,
+	"ponderhit":      PonderHit,
+	"quit":           Quit,
+	"id":             ID,
+	"uciok":          UCIOk,
+	"readyok":        ReadyOk,
+	"bestmove":       BestMove,
+	"copyprotection": CopyProtection,
+	"registration":   Registration,
+	"info":           Info,
+	"option":         Option,
+	"on":             On,
+	"off":            Off,
+	"name":           Name,
+	"value":          Value,
+	"author":         Author,
+	"later":          Later,
+	"code":           Code,
+	"startpos":       Startpos,
+	"fen":            FEN,
+	"moves":          Moves,
+	"searchmoves":    SearchMoves,
+	"ponder":         Ponder,
+	"wtime":          WTime,
+	"btime":          BTime,
+	"winc":           WInc,
+	"binc":           BInc,
+	"movestogo":      MovesToGo,
+	"depth":          Depth,
+	"nodes":          Nodes,
+	"mate":           Mate,
+	"movetime":       MoveTime,
+	"infinite":       Infinite,
+	"checking":       Checking,
+	"ok":             Ok,
+	"error":          Error,
+	"seldepth":       SelDepth,
+	"time":           Time,
+	"pv":             PV,
+	"multipv":        MultiPV,
+	"score":          Score,
+	"cp":             CP,
+	"lowerbound":     LowerBound,
+	"upperbound":     UpperBound,
+	"currmove":       CurrMove,
+	"currmovenumber": CurrMoveNumber,
+	"hashfull":       HashFull,
+	"nps":            NPS,
+	"tbhits":         TBHits,
+	"sbhits":         SBHits,
+	"cpuload":        CPULoad,
+	"string":         String,
+	"refutation":     Refutation,
+	"currline":       CurrLine,
+	"type":           Type,
+	"default":        Default,
+	"min":            Min,
+	"max":            Max,
+	"var":            Var,
+}
+
+// String implements [fmt.Stringer]. Keywords are returned as their text, like
+// "setoption".
+func (t TokenType) String() string {
+	switch t {
+	case EOF:
+		return "EOF"
+	case EOL:
+		return "EOL"
+	case LiteralNumber:
+		return "LiteralNumber"
+	case LiteralString:
+		return "LiteralString"
+	}
+	for text, kt := range keywords {
+		if kt == t {
+			return text
+		}
+	}
+	return fmt.Sprintf("TokenType(%d)", t)
+}
+
+// IsKeyword returns true if t is the type of a keyword, like [SetOption] or
+// [Name].
+func (t TokenType) IsKeyword() bool {
+	return t >= UCI && t <= Var
+}
+
+// A Token is a word of a UCI line.
+type Token struct {
+	Type TokenType
+
+	// The text of the token, or "" for [EOL] and [EOF].
+	Value string
+}
+
+// grammar describes the arguments a command accepts.
+type grammar struct {
+	// The keywords that may follow the command.
+	args []TokenType
+
+	// The keywords that start free text, mapped to the keywords that end it.
+	// Free text without an ending keyword runs to the end of the line.
+	text map[TokenType][]TokenType
+}
+
+// grammars holds the grammar of each command.
+var grammars = map[TokenType]grammar{
+	UCI:        {},
+	Debug:      {args: []TokenType{On, Off}},
+	IsReady:    {},
+	SetOption:  {args: []TokenType{Name, Value}, text: map[TokenType][]TokenType{Name: {Value}, Value: nil}},
+	Register:   {args: []TokenType{Later, Name, Code}, text: map[TokenType][]TokenType{Name: {Code}, Code: nil}},
+	UCINewGame: {},
+	Position:   {args: []TokenType{Startpos, FEN, Moves}},
+	Go: {args: []TokenType{
+		SearchMoves, Ponder, WTime, BTime, WInc, BInc, MovesToGo, Depth, Nodes,
+		Mate, MoveTime, Infinite,
+	}},
+	Stop:           {},
+	PonderHit:      {},
+	Quit:           {},
+	ID:             {args: []TokenType{Name, Author}, text: map[TokenType][]TokenType{Name: nil, Author: nil}},
+	UCIOk:          {},
+	ReadyOk:        {},
+	BestMove:       {args: []TokenType{Ponder}},
+	CopyProtection: {args: []TokenType{Checking, Ok, Error}},
+	Registration:   {args: []TokenType{Checking, Ok, Error}},
+	Info: {
+		args: []TokenType{
+			Depth, SelDepth, Time, Nodes, PV, MultiPV, Score, CP, Mate,
+			LowerBound, UpperBound, CurrMove, CurrMoveNumber, HashFull, NPS,
+			TBHits, SBHits, CPULoad, String, Refutation, CurrLine,
+		},
+		text: map[TokenType][]TokenType{String: nil},
+	},
+	Option: {
+		args: []TokenType{Name, Type, Default, Min, Max, Var},
+		text: map[TokenType][]TokenType{
+			Name:    {Type},
+			Default: {Min, Max, Var},
+			Var:     {Var},
+		},
+	},
+}
+
+// Lex splits line, a single line of UCI text, into tokens. A trailing "\n" or
+// "\r\n" becomes an [EOL] token, and an [EOF] token always comes last. Lex
+// returns an error if line holds more than one line.
+//
+// Whether a word is a keyword depends on its context, since many UCI words are
+// keywords only after certain commands, and some commands take free text:
+//
+//   - Leading words that aren't commands are skipped as [LiteralString]
+//     tokens, as the UCI protocol asks engines to do with unknown tokens. The
+//     first command keyword decides the grammar of the rest of the line.
+//   - After the command, only the keywords of its arguments are recognized.
+//     For example, "depth" is a keyword after "go" and "info", but not after
+//     "position".
+//   - Some keywords start free text, like option or engine names, where no
+//     words are keywords except the one that ends the text. After
+//     "setoption", "name" starts the option name, which runs until "value",
+//     and "value" starts the value, which runs to the end of the line. After
+//     "id", "name" starts the engine name, which runs to the end of the line,
+//     so a later "value" or "author" is part of the name. After "register",
+//     "name" runs until "code", and after "info", "string" runs to the end of
+//     the line.
+//
+// Words that aren't keywords are [LiteralNumber] tokens if they are decimal
+// integers, even within free text, and [LiteralString] tokens otherwise.
+func Lex(line string) ([]Token, error) {
+	text, eol := strings.CutSuffix(line, "\n")
+	if eol {
+		text = strings.TrimSuffix(text, "\r")
+	}
+	if strings.ContainsAny(text, "\r\n") {
+		return nil, fmt.Errorf("lexer: %q holds more than one line", line)
+	}
+
+	var (
+		tokens  []Token
+		g       grammar
+		command bool        // Whether the command has been read.
+		inText  bool        // Whether the word is free text.
+		ends    []TokenType // The keywords that end the free text.
+	)
+	for _, word := range strings.Fields(text) {
+		kt, isKeyword := keywords[word]
+		if !command {
+			if g, command = grammars[kt]; isKeyword && command {
+				tokens = append(tokens, Token{Type: kt, Value: word})
+			} else {
+				tokens = append(tokens, literal(word))
+			}
+			continue
+		}
+
+		if inText {
+			isKeyword = isKeyword && slices.Contains(ends, kt)
+		} else {
+			isKeyword = isKeyword && slices.Contains(g.args, kt)
+		}
+		if !isKeyword {
+			tokens = append(tokens, literal(word))
+			continue
+		}
+		tokens = append(tokens, Token{Type: kt, Value: word})
+		ends, inText = g.text[kt]
+	}
+
+	if eol {
+		tokens = append(tokens, Token{Type: EOL})
+	}
+	return append(tokens, Token{Type: EOF}), nil
+}
+
+// literal returns the token for word, which isn't a keyword.
+func literal(word string) Token {
+	if _, err := strconv.ParseInt(word, 10, 64); err == nil {
+		return Token{Type: LiteralNumber, Value: word}
+	}
+	return Token{Type: LiteralString, Value: word}
+}
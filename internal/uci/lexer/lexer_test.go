@@ -0,0 +1,156 @@
+package lexer
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLex(t *testing.T) {
+	const (
+		num = LiteralNumber
+		str = LiteralString
+	)
+
+	tests := []struct {
+		line string
+		want []TokenType // Not counting EOL and EOF.
+	}{
+		// From the GUI to the engine.
+		{"uci", []TokenType{UCI}},
+		{"debug on", []TokenType{Debug, On}},
+		{"isready", []TokenType{IsReady}},
+		{"setoption name Hash value 32", []TokenType{SetOption, Name, str, Value, num}},
+		{"setoption name Clear Hash", []TokenType{SetOption, Name, str, str}},
+		{"setoption name Skill Level value 20", []TokenType{SetOption, Name, str, str, Value, num}},
+		{"setoption name name value value", []TokenType{SetOption, Name, str, Value, str}},
+		{"setoption name UCI_Opponent value none 2800 human name", []TokenType{SetOption, Name, str, Value, str, num, str, str}},
+		{"register later", []TokenType{Register, Later}},
+		{"register name Stefan MK code 4359874324", []TokenType{Register, Name, str, str, Code, num}},
+		{"ucinewgame", []TokenType{UCINewGame}},
+		{"position startpos moves e2e4 e7e5", []TokenType{Position, Startpos, Moves, str, str}},
+		{
+			"position fen rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1",
+			[]TokenType{Position, FEN, str, str, str, str, num, num},
+		},
+		{"go wtime 300000 btime 300000 winc 0 binc 0", []TokenType{Go, WTime, num, BTime, num, WInc, num, BInc, num}},
+		{"go depth 10 searchmoves e2e4 d2d4", []TokenType{Go, Depth, num, SearchMoves, str, str}},
+		{"go infinite", []TokenType{Go, Infinite}},
+		{"stop", []TokenType{Stop}},
+		{"ponderhit", []TokenType{PonderHit}},
+		{"quit", []TokenType{Quit}},
+
+		// From the engine to the GUI.
+		{"id name Stockfish 16", []TokenType{ID, Name, str, num}},
+		{"id name Engine value author", []TokenType{ID, Name, str, str, str}},
+		{"id author the Stockfish developers", []TokenType{ID, Author, str, str, str}},
+		{"uciok", []TokenType{UCIOk}},
+		{"readyok", []TokenType{ReadyOk}},
+		{"bestmove e2e4 ponder e7e5", []TokenType{BestMove, str, Ponder, str}},
+		{"copyprotection checking", []TokenType{CopyProtection, Checking}},
+		{"registration error", []TokenType{Registration, Error}},
+		{
+			"info depth 10 seldepth 14 multipv 1 score cp -20 lowerbound nodes 4000 nps 40000 pv e2e4 e7e5",
+			[]TokenType{Info, Depth, num, SelDepth, num, MultiPV, num, Score, CP, num, LowerBound, Nodes, num, NPS, num, PV, str, str},
+		},
+		{"info currmove e2e4 currmovenumber 1", []TokenType{Info, CurrMove, str, CurrMoveNumber, num}},
+		{"info string depth 3 is enough", []TokenType{Info, String, str, num, str, str}},
+		{"option name Hash type spin default 16 min 1 max 33554432", []TokenType{Option, Name, str, Type, str, Default, num, Min, num, Max, num}},
+		{"option name Style type combo default Normal var Solid var Risky", []TokenType{Option, Name, str, Type, str, Default, str, Var, str, Var, str}},
+		{"option name Clear Hash type button", []TokenType{Option, Name, str, str, Type, str}},
+
+		// Keywords of other commands, and unknown tokens.
+		{"position startpos depth 3", []TokenType{Position, Startpos, str, num}},
+		{"joho debug on", []TokenType{str, Debug, On}},
+		{"hello world", []TokenType{str, str}},
+		{"", nil},
+		{"  go   depth\t3 ", []TokenType{Go, Depth, num}},
+	}
+
+	for _, test := range tests {
+		got, err := Lex(test.line + "\n")
+		if err != nil {
+			t.Errorf("Lex(%q): %v", test.line, err)
+			continue
+		}
+
+		want := append(slices.Clone(test.want), EOL, EOF)
+		var types []TokenType
+		for _, tok := range got {
+			types = append(types, tok.Type)
+		}
+		if !slices.Equal(types, want) {
+			t.Errorf("Lex(%q): got types %v, want %v", test.line, types, want)
+			continue
+		}
+
+		values := append(strings.Fields(test.line), "", "")
+		for i, tok := range got {
+			if tok.Value != values[i] {
+				t.Errorf("Lex(%q): got value %q for token %d, want %q", test.line, tok.Value, i, values[i])
+			}
+		}
+	}
+}
+
+func TestLex_lineEndings(t *testing.T) {
+	tests := []struct {
+		line string
+		want []TokenType
+	}{
+		{"isready", []TokenType{IsReady, EOF}},
+		{"isready\n", []TokenType{IsReady, EOL, EOF}},
+		{"isready\r\n", []TokenType{IsReady, EOL, EOF}},
+		{"\n", []TokenType{EOL, EOF}},
+	}
+
+	for _, test := range tests {
+		got, err := Lex(test.line)
+		if err != nil {
+			t.Errorf("Lex(%q): %v", test.line, err)
+			continue
+		}
+		var types []TokenType
+		for _, tok := range got {
+			types = append(types, tok.Type)
+		}
+		if !slices.Equal(types, test.want) {
+			t.Errorf("Lex(%q): got types %v, want %v", test.line, types, test.want)
+		}
+	}
+}
+
+func TestLex_error(t *testing.T) {
+	for _, line := range []string{"uci\nisready", "uci\n\n", "uci\risready"} {
+		if _, err := Lex(line); err == nil {
+			t.Errorf("Lex(%q): got nil error, want one", line)
+		}
+	}
+}
+
+func TestTokenType_String(t *testing.T) {
+	tests := []struct {
+		t       TokenType
+		want    string
+		keyword bool
+	}{
+		{EOF, "EOF", false},
+		{EOL, "EOL", false},
+		{LiteralNumber, "LiteralNumber", false},
+		{LiteralString, "LiteralString", false},
+		{UCI, "uci", true},
+		{SetOption, "setoption", true},
+		{String, "string", true},
+		{Var, "var", true},
+		{TokenType(0), "TokenType(0)", false},
+	}
+
+	for _, test := range tests {
+		if got := test.t.String(); got != test.want {
+			t.Errorf("String(%d): got %q, want %q", int(test.t), got, test.want)
+		}
+		if got := test.t.IsKeyword(); got != test.keyword {
+			t.Errorf("IsKeyword(%v): got %t, want %t", test.t, got, test.keyword)
+		}
+	}
+}
@@ -2,9 +2,13 @@
 package uci
 
 import (
-	"errors"
 	"fmt"
 	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
 )
 
 // UCI represents a "uci" command.
@@ -13,7 +17,7 @@ type UCI struct{}
 // UnmarshalText implements [encoding.TextUnmarshaler].
 func (m *UCI) UnmarshalText(text []byte) error {
 	if string(text) != "uci" {
-		return errors.New("not a uci command")
+		return parseError("uci", "", ErrWrongCommand)
 	}
 	return nil
 }
@@ -29,7 +33,7 @@ type IsReady struct{}
 // UnmarshalText implements [encoding.TextUnmarshaler].
 func (m *IsReady) UnmarshalText(text []byte) error {
 	if string(text) != "isready" {
-		return errors.New("not an isready command")
+		return parseError("isready", "", ErrWrongCommand)
 	}
 	return nil
 }
@@ -39,13 +43,86 @@ func (m *IsReady) AppendText(b []byte) ([]byte, error) {
 	return fmt.Append(b, "isready"), nil
 }
 
+// SetOption represents a "setoption" command.
+type SetOption struct {
+	// The name of the option, which may contain spaces.
+	Name string
+
+	// The value of the option, if any. Button options have no value.
+	Value string
+}
+
+var regexpSetOption = regexp.MustCompile(`^setoption name (.+?)(?: value (.*))?$`)
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (m *SetOption) UnmarshalText(text []byte) error {
+	subs := regexpSetOption.FindSubmatch(text)
+	if subs == nil {
+		return parseError("setoption", "", ErrInvalidArg)
+	}
+	m.Name = string(subs[1])
+	m.Value = string(subs[2])
+	return nil
+}
+
+// AppendText implements [encoding.TextAppender].
+func (m *SetOption) AppendText(b []byte) ([]byte, error) {
+	if m.Name == "" {
+		return nil, fmt.Errorf("setoption command: %w: name", ErrMissingArg)
+	}
+	b = fmt.Appendf(b, "setoption name %s", m.Name)
+	if m.Value != "" {
+		b = fmt.Appendf(b, " value %s", m.Value)
+	}
+	return b, nil
+}
+
+// Register represents a "register" command.
+type Register struct {
+	// Later is true if the user will register later, as in "register later".
+	Later bool
+
+	// The name to register, which may contain spaces.
+	Name string
+
+	// The registration code. It is the rest of the line after "code".
+	Code string
+}
+
+var regexpRegister = regexp.MustCompile(`^register (?:(later)|name (.+?) code (.+))$`)
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (m *Register) UnmarshalText(text []byte) error {
+	subs := regexpRegister.FindSubmatch(text)
+	if subs == nil {
+		return parseError("register", "", ErrInvalidArg)
+	}
+	m.Later = len(subs[1]) != 0
+	m.Name = string(subs[2])
+	m.Code = string(subs[3])
+	return nil
+}
+
+// AppendText implements [encoding.TextAppender].
+func (m *Register) AppendText(b []byte) ([]byte, error) {
+	switch {
+	case m.Later && (m.Name != "" || m.Code != ""):
+		return nil, fmt.Errorf("register command: %w: later with name or code", ErrConflictingArgs)
+	case m.Later:
+		return fmt.Append(b, "register later"), nil
+	case m.Name == "" || m.Code == "":
+		return nil, fmt.Errorf("register command: %w: name and code", ErrMissingArg)
+	}
+	return fmt.Appendf(b, "register name %s code %s", m.Name, m.Code), nil
+}
+
 // UCINewGame represents a "ucinewgame" command.
 type UCINewGame struct{}
 
 // UnmarshalText implements [encoding.TextUnmarshaler].
 func (m *UCINewGame) UnmarshalText(text []byte) error {
 	if string(text) != "ucinewgame" {
-		return errors.New("not a ucinewgame command")
+		return parseError("ucinewgame", "", ErrWrongCommand)
 	}
 	return nil
 }
@@ -55,13 +132,325 @@ func (m *UCINewGame) AppendText(b []byte) ([]byte, error) {
 	return fmt.Append(b, "ucinewgame"), nil
 }
 
+// Position represents a "position" command.
+type Position struct {
+	// Startpos is true if the position starts from the starting position.
+	Startpos bool
+
+	// FEN is the position to start from in Forsyth-Edwards Notation, if
+	// Startpos is false.
+	FEN string
+
+	// Moves to play from the starting position, in long algebraic notation.
+	Moves []string
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (m *Position) UnmarshalText(text []byte) error {
+	fields := strings.Fields(string(text))
+	if len(fields) < 2 || fields[0] != "position" {
+		return parseError("position", "", ErrWrongCommand)
+	}
+
+	*m = Position{}
+
+	rest := fields[2:]
+	switch fields[1] {
+	case "startpos":
+		m.Startpos = true
+		if len(rest) > 0 && rest[0] == "fen" {
+			return parseError("position", "startpos", ErrConflictingArgs)
+		}
+	case "fen":
+		i := 0
+		for i < len(rest) && rest[i] != "moves" {
+			i++
+		}
+		if i == 0 {
+			return parseError("position", "fen", ErrMissingArg)
+		}
+		if slices.Contains(rest[:i], "startpos") {
+			return parseError("position", "startpos", ErrConflictingArgs)
+		}
+		m.FEN = strings.Join(rest[:i], " ")
+		rest = rest[i:]
+	default:
+		return parseError("position", fields[1], ErrUnexpectedToken)
+	}
+
+	if len(rest) == 0 {
+		return nil
+	}
+	if rest[0] != "moves" {
+		return parseError("position", rest[0], ErrUnexpectedToken)
+	}
+	if len(rest) > 1 {
+		m.Moves = rest[1:]
+	}
+	return nil
+}
+
+// AppendText implements [encoding.TextAppender].
+func (m *Position) AppendText(b []byte) ([]byte, error) {
+	if m.Startpos && m.FEN != "" {
+		return nil, fmt.Errorf("position command: %w: startpos and fen", ErrConflictingArgs)
+	}
+	if !m.Startpos && m.FEN == "" {
+		return nil, fmt.Errorf("position command: %w: startpos or fen", ErrMissingArg)
+	}
+
+	b = fmt.Append(b, "position ")
+	if m.Startpos {
+		b = fmt.Append(b, "startpos")
+	} else {
+		b = fmt.Appendf(b, "fen %s", m.FEN)
+	}
+	if len(m.Moves) > 0 {
+		b = fmt.Appendf(b, " moves %s", strings.Join(m.Moves, " "))
+	}
+	return b, nil
+}
+
+// Go represents a "go" command.
+//
+// The zero value for each field indicates it is not present.
+type Go struct {
+	// Restrict search to these moves, in long algebraic notation.
+	SearchMoves []string
+
+	// Search in pondering mode.
+	Ponder bool
+
+	// Time left on each player's clock.
+	WTime, BTime time.Duration
+
+	// Increment per move for each player.
+	WInc, BInc time.Duration
+
+	// Moves until the next time control.
+	MovesToGo int
+
+	// Maximum search depth in plies.
+	Depth int
+
+	// Maximum number of nodes to search.
+	Nodes int
+
+	// Search for a mate in this many moves.
+	Mate int
+
+	// Search for exactly this long.
+	MoveTime time.Duration
+
+	// Search until a "stop" command.
+	Infinite bool
+
+	// Count the leaf nodes to this depth instead of searching. This is a
+	// common extension to the protocol for debugging move generation, not
+	// part of the protocol itself.
+	Perft int
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (m *Go) UnmarshalText(text []byte) error {
+	fields := strings.Fields(string(text))
+	if len(fields) == 0 || fields[0] != "go" {
+		return parseError("go", "", ErrWrongCommand)
+	}
+
+	*m = Go{}
+
+	for i := 1; i < len(fields); i++ {
+		switch key := fields[i]; key {
+		case "searchmoves":
+			// Search moves consume tokens until the next keyword.
+			for i+1 < len(fields) && !isGoKeyword(fields[i+1]) {
+				i++
+				m.SearchMoves = append(m.SearchMoves, fields[i])
+			}
+		case "ponder":
+			m.Ponder = true
+		case "infinite":
+			m.Infinite = true
+		case "wtime", "btime", "winc", "binc", "movestogo", "depth", "nodes", "mate", "movetime", "perft":
+			if i+1 >= len(fields) {
+				return parseError("go", key, ErrMissingArg)
+			}
+			i++
+			n, err := strconv.Atoi(fields[i])
+			if err != nil {
+				return parseError("go", fields[i], ErrInvalidArg)
+			}
+			ms := time.Duration(n) * time.Millisecond
+			switch key {
+			case "wtime":
+				m.WTime = ms
+			case "btime":
+				m.BTime = ms
+			case "winc":
+				m.WInc = ms
+			case "binc":
+				m.BInc = ms
+			case "movestogo":
+				m.MovesToGo = n
+			case "depth":
+				m.Depth = n
+			case "nodes":
+				m.Nodes = n
+			case "mate":
+				m.Mate = n
+			case "movetime":
+				m.MoveTime = ms
+			case "perft":
+				m.Perft = n
+			}
+		default:
+			return parseError("go", key, ErrUnexpectedToken)
+		}
+	}
+
+	return nil
+}
+
+// isGoKeyword returns true if s is a keyword of the go command.
+func isGoKeyword(s string) bool {
+	switch s {
+	case "searchmoves", "ponder", "wtime", "btime", "winc", "binc", "movestogo",
+		"depth", "nodes", "mate", "movetime", "infinite", "perft":
+		return true
+	default:
+		return false
+	}
+}
+
+// AppendText implements [encoding.TextAppender].
+func (m *Go) AppendText(b []byte) ([]byte, error) {
+	b = fmt.Append(b, "go")
+	if len(m.SearchMoves) > 0 {
+		b = fmt.Appendf(b, " searchmoves %s", strings.Join(m.SearchMoves, " "))
+	}
+	if m.Ponder {
+		b = fmt.Append(b, " ponder")
+	}
+	if m.WTime != 0 {
+		b = fmt.Appendf(b, " wtime %d", m.WTime.Milliseconds())
+	}
+	if m.BTime != 0 {
+		b = fmt.Appendf(b, " btime %d", m.BTime.Milliseconds())
+	}
+	if m.WInc != 0 {
+		b = fmt.Appendf(b, " winc %d", m.WInc.Milliseconds())
+	}
+	if m.BInc != 0 {
+		b = fmt.Appendf(b, " binc %d", m.BInc.Milliseconds())
+	}
+	if m.MovesToGo != 0 {
+		b = fmt.Appendf(b, " movestogo %d", m.MovesToGo)
+	}
+	if m.Depth != 0 {
+		b = fmt.Appendf(b, " depth %d", m.Depth)
+	}
+	if m.Nodes != 0 {
+		b = fmt.Appendf(b, " nodes %d", m.Nodes)
+	}
+	if m.Mate != 0 {
+		b = fmt.Appendf(b, " mate %d", m.Mate)
+	}
+	if m.MoveTime != 0 {
+		b = fmt.Appendf(b, " movetime %d", m.MoveTime.Milliseconds())
+	}
+	if m.Infinite {
+		b = fmt.Append(b, " infinite")
+	}
+	if m.Perft != 0 {
+		b = fmt.Appendf(b, " perft %d", m.Perft)
+	}
+	return b, nil
+}
+
+// PerftMove reports the number of leaf nodes below a root move, in response
+// to a "go perft" command. Like "go perft", it is an extension to the
+// protocol.
+//
+// It is written like "e2e4: 20".
+type PerftMove struct {
+	Move  string
+	Nodes int
+}
+
+var regexpPerftMove = regexp.MustCompile(`^(\S+): (\d+)$`)
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (m *PerftMove) UnmarshalText(text []byte) error {
+	subs := regexpPerftMove.FindSubmatch(text)
+	if subs == nil {
+		return parseError("perft", "", ErrWrongCommand)
+	}
+	n, err := strconv.Atoi(string(subs[2]))
+	if err != nil {
+		return parseError("perft", string(subs[2]), ErrInvalidArg)
+	}
+	m.Move, m.Nodes = string(subs[1]), n
+	return nil
+}
+
+// AppendText implements [encoding.TextAppender].
+func (m *PerftMove) AppendText(b []byte) ([]byte, error) {
+	return fmt.Appendf(b, "%s: %d", m.Move, m.Nodes), nil
+}
+
+// PerftTotal reports the total number of leaf nodes counted in response to a
+// "go perft" command. Like "go perft", it is an extension to the protocol.
+//
+// It is written like "Nodes searched: 400".
+type PerftTotal struct {
+	Nodes int
+}
+
+var regexpPerftTotal = regexp.MustCompile(`^Nodes searched: (\d+)$`)
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (m *PerftTotal) UnmarshalText(text []byte) error {
+	subs := regexpPerftTotal.FindSubmatch(text)
+	if subs == nil {
+		return parseError("perft", "", ErrWrongCommand)
+	}
+	n, err := strconv.Atoi(string(subs[1]))
+	if err != nil {
+		return parseError("perft", string(subs[1]), ErrInvalidArg)
+	}
+	m.Nodes = n
+	return nil
+}
+
+// AppendText implements [encoding.TextAppender].
+func (m *PerftTotal) AppendText(b []byte) ([]byte, error) {
+	return fmt.Appendf(b, "Nodes searched: %d", m.Nodes), nil
+}
+
+// Stop represents a "stop" command.
+type Stop struct{}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (m *Stop) UnmarshalText(text []byte) error {
+	if string(text) != "stop" {
+		return parseError("stop", "", ErrWrongCommand)
+	}
+	return nil
+}
+
+// AppendText implements [encoding.TextAppender].
+func (m *Stop) AppendText(b []byte) ([]byte, error) {
+	return fmt.Append(b, "stop"), nil
+}
+
 // Quit represents a "quit" command.
 type Quit struct{}
 
 // UnmarshalText implements [encoding.TextUnmarshaler].
 func (m *Quit) UnmarshalText(text []byte) error {
 	if string(text) != "quit" {
-		return errors.New("not a quit command")
+		return parseError("quit", "", ErrWrongCommand)
 	}
 	return nil
 }
@@ -96,16 +485,16 @@ func (m *ID) UnmarshalText(text []byte) error {
 		return nil
 	}
 
-	return errors.New("invalid id command")
+	return parseError("id", "", ErrInvalidArg)
 }
 
 // AppendText implements [encoding.TextAppender].
 func (m *ID) AppendText(b []byte) ([]byte, error) {
 	if m.Name != "" && m.Author != "" {
-		return nil, errors.New("cannot specify both name and author")
+		return nil, fmt.Errorf("id command: %w: name and author", ErrConflictingArgs)
 	}
 	if m.Name == "" && m.Author == "" {
-		return nil, errors.New("must specify either name or author")
+		return nil, fmt.Errorf("id command: %w: name or author", ErrMissingArg)
 	}
 
 	b = fmt.Append(b, "id ")
@@ -125,7 +514,7 @@ type UCIOk struct{}
 // UnmarshalText implements [encoding.TextUnmarshaler].
 func (m *UCIOk) UnmarshalText(text []byte) error {
 	if string(text) != "uciok" {
-		return errors.New("not a uciok command")
+		return parseError("uciok", "", ErrWrongCommand)
 	}
 	return nil
 }
@@ -141,7 +530,7 @@ type ReadyOk struct{}
 // UnmarshalText implements [encoding.TextUnmarshaler].
 func (m *ReadyOk) UnmarshalText(text []byte) error {
 	if string(text) != "readyok" {
-		return errors.New("not a readyok command")
+		return parseError("readyok", "", ErrWrongCommand)
 	}
 	return nil
 }
@@ -150,3 +539,345 @@ func (m *ReadyOk) UnmarshalText(text []byte) error {
 func (m *ReadyOk) AppendText(b []byte) ([]byte, error) {
 	return fmt.Append(b, "readyok"), nil
 }
+
+// BestMove represents a "bestmove" command.
+type BestMove struct {
+	// The best move, in long algebraic notation.
+	Move string
+
+	// The move to ponder on, if any.
+	Ponder string
+}
+
+var regexpBestMove = regexp.MustCompile(`^bestmove (\S+)(?: ponder (\S+))?$`)
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (m *BestMove) UnmarshalText(text []byte) error {
+	subs := regexpBestMove.FindSubmatch(text)
+	if subs == nil {
+		return parseError("bestmove", "", ErrInvalidArg)
+	}
+	m.Move = string(subs[1])
+	m.Ponder = string(subs[2])
+	return nil
+}
+
+// AppendText implements [encoding.TextAppender].
+func (m *BestMove) AppendText(b []byte) ([]byte, error) {
+	if m.Move == "" {
+		return nil, fmt.Errorf("bestmove command: %w: move", ErrMissingArg)
+	}
+	b = fmt.Appendf(b, "bestmove %s", m.Move)
+	if m.Ponder != "" {
+		b = fmt.Appendf(b, " ponder %s", m.Ponder)
+	}
+	return b, nil
+}
+
+// Score represents an engine's evaluation of a position, from the engine's
+// point of view.
+type Score struct {
+	// The score in centipawns. It is ignored if Mate is nonzero.
+	CP int
+
+	// If positive, the engine mates in this many moves. If negative, the
+	// engine gets mated in this many moves.
+	Mate int
+
+	// Whether the score is only a lower or upper bound.
+	Lowerbound, Upperbound bool
+}
+
+// Info represents an "info" command.
+//
+// The zero value for each field indicates it is not present.
+type Info struct {
+	// Search depth in plies.
+	Depth int
+
+	// Selective search depth in plies.
+	SelDepth int
+
+	// The number of the principal variation this line reports, from 1, when
+	// the engine reports several.
+	MultiPV int
+
+	// Time searched.
+	Time time.Duration
+
+	// Nodes searched.
+	Nodes int
+
+	// The principal variation, in long algebraic notation.
+	PV []string
+
+	// The score.
+	Score *Score
+
+	// Nodes searched per second.
+	NPS int
+
+	// Permille of the hash table in use.
+	HashFull int
+
+	// Positions found in endgame tablebases.
+	TBHits int
+
+	// Positions found in Shredder endgame databases.
+	SBHits int
+
+	// Permille of the CPU in use.
+	CPULoad int
+
+	// The move currently being searched, in long algebraic notation.
+	CurrMove string
+
+	// The number of the move currently being searched, from 1.
+	CurrMoveNumber int
+
+	// The line currently being searched, in long algebraic notation.
+	CurrLine []string
+
+	// The CPU searching CurrLine, from 1, if the engine uses several.
+	CurrLineCPU int
+
+	// A move found to be worse than the best move, followed by the line that
+	// refutes it, in long algebraic notation.
+	Refutation []string
+
+	// A string to display. It always appears last, since it extends to the
+	// end of the line.
+	Str string
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler]. Fields may appear in
+// any order. The moves of "pv", "refutation", and "currline" extend to the next
+// keyword, and "string" extends to the end of the line.
+func (m *Info) UnmarshalText(text []byte) error {
+	fields := strings.Fields(string(text))
+	if len(fields) < 2 || fields[0] != "info" {
+		return parseError("info", "", ErrWrongCommand)
+	}
+
+	*m = Info{}
+
+	for i := 1; i < len(fields); i++ {
+		switch key := fields[i]; key {
+		case "pv", "refutation":
+			// A line of moves consumes tokens until the next keyword.
+			var moves []string
+			for i+1 < len(fields) && !isInfoKeyword(fields[i+1]) {
+				i++
+				moves = append(moves, fields[i])
+			}
+			switch key {
+			case "pv":
+				m.PV = moves
+			case "refutation":
+				m.Refutation = moves
+			}
+		case "currline":
+			// The line may start with the number of the CPU searching it.
+			if i+1 < len(fields) {
+				if n, err := strconv.Atoi(fields[i+1]); err == nil {
+					m.CurrLineCPU = n
+					i++
+				}
+			}
+			for i+1 < len(fields) && !isInfoKeyword(fields[i+1]) {
+				i++
+				m.CurrLine = append(m.CurrLine, fields[i])
+			}
+		case "currmove":
+			if i+1 >= len(fields) {
+				return parseError("info", key, ErrMissingArg)
+			}
+			i++
+			m.CurrMove = fields[i]
+		case "string":
+			// The string consumes the rest of the line, even if it contains
+			// keywords.
+			m.Str = restAfter(string(text), i+1)
+			return nil
+		case "score":
+			n, err := m.unmarshalScore(fields[i+1:])
+			if err != nil {
+				return err
+			}
+			i += n
+		case "depth", "seldepth", "multipv", "time", "nodes", "nps", "hashfull", "tbhits", "sbhits", "cpuload", "currmovenumber":
+			if i+1 >= len(fields) {
+				return parseError("info", key, ErrMissingArg)
+			}
+			i++
+			n, err := strconv.Atoi(fields[i])
+			if err != nil {
+				return parseError("info", fields[i], ErrInvalidArg)
+			}
+			switch key {
+			case "depth":
+				m.Depth = n
+			case "seldepth":
+				m.SelDepth = n
+			case "multipv":
+				m.MultiPV = n
+			case "time":
+				m.Time = time.Duration(n) * time.Millisecond
+			case "nodes":
+				m.Nodes = n
+			case "nps":
+				m.NPS = n
+			case "hashfull":
+				m.HashFull = n
+			case "tbhits":
+				m.TBHits = n
+			case "sbhits":
+				m.SBHits = n
+			case "cpuload":
+				m.CPULoad = n
+			case "currmovenumber":
+				m.CurrMoveNumber = n
+			}
+		default:
+			return parseError("info", key, ErrUnexpectedToken)
+		}
+	}
+
+	return nil
+}
+
+// unmarshalScore parses the tokens following "score" in an info command and
+// returns the number of tokens consumed.
+func (m *Info) unmarshalScore(fields []string) (int, error) {
+	if len(fields) < 2 {
+		return 0, parseError("info", "score", ErrMissingArg)
+	}
+
+	n, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, parseError("info", fields[1], ErrInvalidArg)
+	}
+
+	m.Score = new(Score)
+	switch fields[0] {
+	case "cp":
+		m.Score.CP = n
+	case "mate":
+		m.Score.Mate = n
+	default:
+		return 0, parseError("info", fields[0], ErrInvalidArg)
+	}
+
+	consumed := 2
+	if len(fields) > 2 {
+		switch fields[2] {
+		case "lowerbound":
+			m.Score.Lowerbound = true
+			consumed++
+		case "upperbound":
+			m.Score.Upperbound = true
+			consumed++
+		}
+	}
+	return consumed, nil
+}
+
+// restAfter returns s after its first n whitespace-separated fields, without
+// leading whitespace.
+func restAfter(s string, n int) string {
+	for range n {
+		s = strings.TrimLeftFunc(s, unicode.IsSpace)
+		i := strings.IndexFunc(s, unicode.IsSpace)
+		if i < 0 {
+			return ""
+		}
+		s = s[i:]
+	}
+	return strings.TrimLeftFunc(s, unicode.IsSpace)
+}
+
+// isInfoKeyword returns true if s is a keyword of the info command.
+func isInfoKeyword(s string) bool {
+	switch s {
+	case "depth", "seldepth", "multipv", "time", "nodes", "pv", "score", "nps", "hashfull", "tbhits", "sbhits",
+		"cpuload", "currmove", "currmovenumber", "currline", "refutation", "string":
+		return true
+	default:
+		return false
+	}
+}
+
+// AppendText implements [encoding.TextAppender].
+func (m *Info) AppendText(b []byte) ([]byte, error) {
+	b = fmt.Append(b, "info")
+	n := len(b)
+	if m.Depth != 0 {
+		b = fmt.Appendf(b, " depth %d", m.Depth)
+	}
+	if m.SelDepth != 0 {
+		b = fmt.Appendf(b, " seldepth %d", m.SelDepth)
+	}
+	if m.MultiPV != 0 {
+		b = fmt.Appendf(b, " multipv %d", m.MultiPV)
+	}
+	if m.Score != nil {
+		if m.Score.Mate != 0 {
+			b = fmt.Appendf(b, " score mate %d", m.Score.Mate)
+		} else {
+			b = fmt.Appendf(b, " score cp %d", m.Score.CP)
+		}
+		if m.Score.Lowerbound {
+			b = fmt.Append(b, " lowerbound")
+		}
+		if m.Score.Upperbound {
+			b = fmt.Append(b, " upperbound")
+		}
+	}
+	if m.Nodes != 0 {
+		b = fmt.Appendf(b, " nodes %d", m.Nodes)
+	}
+	if m.NPS != 0 {
+		b = fmt.Appendf(b, " nps %d", m.NPS)
+	}
+	if m.HashFull != 0 {
+		b = fmt.Appendf(b, " hashfull %d", m.HashFull)
+	}
+	if m.TBHits != 0 {
+		b = fmt.Appendf(b, " tbhits %d", m.TBHits)
+	}
+	if m.SBHits != 0 {
+		b = fmt.Appendf(b, " sbhits %d", m.SBHits)
+	}
+	if m.CPULoad != 0 {
+		b = fmt.Appendf(b, " cpuload %d", m.CPULoad)
+	}
+	if m.Time != 0 {
+		b = fmt.Appendf(b, " time %d", m.Time.Milliseconds())
+	}
+	if m.CurrMove != "" {
+		b = fmt.Appendf(b, " currmove %s", m.CurrMove)
+	}
+	if m.CurrMoveNumber != 0 {
+		b = fmt.Appendf(b, " currmovenumber %d", m.CurrMoveNumber)
+	}
+	if len(m.PV) > 0 {
+		b = fmt.Appendf(b, " pv %s", strings.Join(m.PV, " "))
+	}
+	if len(m.Refutation) > 0 {
+		b = fmt.Appendf(b, " refutation %s", strings.Join(m.Refutation, " "))
+	}
+	if len(m.CurrLine) > 0 {
+		b = fmt.Append(b, " currline")
+		if m.CurrLineCPU != 0 {
+			b = fmt.Appendf(b, " %d", m.CurrLineCPU)
+		}
+		b = fmt.Appendf(b, " %s", strings.Join(m.CurrLine, " "))
+	}
+	if m.Str != "" {
+		b = fmt.Appendf(b, " string %s", m.Str)
+	}
+	if len(b) == n {
+		return nil, fmt.Errorf("info command: %w", ErrMissingArg)
+	}
+	return b, nil
+}
@@ -1,7 +1,9 @@
 package uci
 
 import (
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestID_UnmarshalText(t *testing.T) {
@@ -107,3 +109,334 @@ func TestUCI_AppendText(t *testing.T) {
 		})
 	}
 }
+
+func TestInfo_AppendText(t *testing.T) {
+	tests := []struct {
+		name    string
+		message Info
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "depth",
+			message: Info{Depth: 5},
+			want:    "info depth 5",
+		},
+		{
+			name: "search summary",
+			message: Info{
+				Depth:    5,
+				SelDepth: 7,
+				Score:    &Score{CP: 34},
+				Nodes:    1000,
+				Time:     250 * time.Millisecond,
+				PV:       []string{"e2e4", "e7e5"},
+			},
+			want: "info depth 5 seldepth 7 score cp 34 nodes 1000 time 250 pv e2e4 e7e5",
+		},
+		{
+			name:    "zero centipawns",
+			message: Info{Score: &Score{}},
+			want:    "info score cp 0",
+		},
+		{
+			name:    "getting mated",
+			message: Info{Score: &Score{Mate: -2}},
+			want:    "info score mate -2",
+		},
+		{
+			name:    "string",
+			message: Info{Depth: 1, Str: "hello world"},
+			want:    "info depth 1 string hello world",
+		},
+		{
+			name:    "refutation",
+			message: Info{Depth: 2, PV: []string{"e2e4"}, Refutation: []string{"d2d4", "d7d5"}},
+			want:    "info depth 2 pv e2e4 refutation d2d4 d7d5",
+		},
+		{
+			name:    "empty",
+			message: Info{},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.message.AppendText(nil)
+			gotErr := (err != nil)
+
+			if string(got) != test.want {
+				t.Errorf("%#v.AppendText(nil): got %q, want %q", test.message, got, test.want)
+			}
+			if gotErr != test.wantErr {
+				t.Errorf("%#v.AppendText(nil): gotErr %v, wantErr %v", test.message, gotErr, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestInfo_UnmarshalText(t *testing.T) {
+	text := "info depth 5 seldepth 7 score cp -34 upperbound nodes 1000 nps 4000 time 250 pv e2e4 e7e5"
+	want := Info{
+		Depth:    5,
+		SelDepth: 7,
+		Score:    &Score{CP: -34, Upperbound: true},
+		Nodes:    1000,
+		NPS:      4000,
+		Time:     250 * time.Millisecond,
+		PV:       []string{"e2e4", "e7e5"},
+	}
+
+	var got Info
+	if err := got.UnmarshalText([]byte(text)); err != nil {
+		t.Fatalf("Info.UnmarshalText(%q): %v", text, err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Info.UnmarshalText(%q): got %#v, want %#v", text, got, want)
+	}
+}
+
+func TestInfo_UnmarshalText_order(t *testing.T) {
+	texts := []string{
+		"info depth 5 seldepth 7 score cp -34 upperbound nodes 1000 time 250 pv e2e4 e7e5 refutation d2d4 d7d5",
+		"info pv e2e4 e7e5 time 250 refutation d2d4 d7d5 nodes 1000 score cp -34 upperbound seldepth 7 depth 5",
+		"info refutation d2d4 d7d5 score cp -34 upperbound pv e2e4 e7e5 depth 5 nodes 1000 seldepth 7 time 250",
+	}
+	want := Info{
+		Depth:      5,
+		SelDepth:   7,
+		Score:      &Score{CP: -34, Upperbound: true},
+		Nodes:      1000,
+		Time:       250 * time.Millisecond,
+		PV:         []string{"e2e4", "e7e5"},
+		Refutation: []string{"d2d4", "d7d5"},
+	}
+
+	for _, text := range texts {
+		var got Info
+		if err := got.UnmarshalText([]byte(text)); err != nil {
+			t.Errorf("Info.UnmarshalText(%q): %v", text, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Info.UnmarshalText(%q): got %#v, want %#v", text, got, want)
+		}
+	}
+}
+
+func TestInfo_UnmarshalText_allKeywords(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want Info
+	}{
+		{
+			name: "stockfish",
+			text: "info depth 10 seldepth 13 multipv 1 score cp 20 nodes 12345 nps 600000 hashfull 5 tbhits 0 time 20 pv e2e4 e7e5 g1f3",
+			want: Info{
+				Depth:    10,
+				SelDepth: 13,
+				MultiPV:  1,
+				Score:    &Score{CP: 20},
+				Nodes:    12345,
+				NPS:      600000,
+				HashFull: 5,
+				Time:     20 * time.Millisecond,
+				PV:       []string{"e2e4", "e7e5", "g1f3"},
+			},
+		},
+		{
+			name: "current move",
+			text: "info depth 12 currmove d2d4 currmovenumber 3",
+			want: Info{Depth: 12, CurrMove: "d2d4", CurrMoveNumber: 3},
+		},
+		{
+			name: "pv stops at keywords",
+			text: "info pv e2e4 e7e5 tbhits 7 pv g1f3 sbhits 2 cpuload 950",
+			want: Info{PV: []string{"g1f3"}, TBHits: 7, SBHits: 2, CPULoad: 950},
+		},
+		{
+			name: "current line",
+			text: "info currline e2e4 e7e5 depth 3",
+			want: Info{CurrLine: []string{"e2e4", "e7e5"}, Depth: 3},
+		},
+		{
+			name: "current line with CPU",
+			text: "info currline 2 d2d4 d7d5",
+			want: Info{CurrLineCPU: 2, CurrLine: []string{"d2d4", "d7d5"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got Info
+			if err := got.UnmarshalText([]byte(test.text)); err != nil {
+				t.Fatalf("Info.UnmarshalText(%q): %v", test.text, err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Info.UnmarshalText(%q): got %#v, want %#v", test.text, got, test.want)
+			}
+
+			// The fields survive a round trip.
+			b, err := got.AppendText(nil)
+			if err != nil {
+				t.Fatalf("%#v.AppendText(nil): %v", got, err)
+			}
+			var again Info
+			if err := again.UnmarshalText(b); err != nil || !reflect.DeepEqual(again, got) {
+				t.Errorf("round trip through %q: got %#v, %v, want %#v", b, again, err, got)
+			}
+		})
+	}
+}
+
+func TestInfo_UnmarshalText_string(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want Info
+	}{
+		{
+			name: "keywords",
+			text: "info depth 5 string best: e2e4 score unclear",
+			want: Info{Depth: 5, Str: "best: e2e4 score unclear"},
+		},
+		{
+			name: "only string",
+			text: "info string pv depth 3",
+			want: Info{Str: "pv depth 3"},
+		},
+		{
+			name: "string in string",
+			text: "info nodes 10 string string theory",
+			want: Info{Nodes: 10, Str: "string theory"},
+		},
+		{
+			name: "extra spaces",
+			text: "info  depth 2  string  hello  world",
+			want: Info{Depth: 2, Str: "hello  world"},
+		},
+		{
+			name: "empty string",
+			text: "info depth 2 string",
+			want: Info{Depth: 2},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got Info
+			if err := got.UnmarshalText([]byte(test.text)); err != nil {
+				t.Fatalf("Info.UnmarshalText(%q): %v", test.text, err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Info.UnmarshalText(%q): got %#v, want %#v", test.text, got, test.want)
+			}
+		})
+	}
+}
+
+func TestSetOption_UnmarshalText(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    SetOption
+		wantErr bool
+	}{
+		{
+			name: "value",
+			text: "setoption name Hash value 32",
+			want: SetOption{Name: "Hash", Value: "32"},
+		},
+		{
+			name: "spaces",
+			text: "setoption name Clear Hash Now value a b c",
+			want: SetOption{Name: "Clear Hash Now", Value: "a b c"},
+		},
+		{
+			name: "button",
+			text: "setoption name Clear Hash",
+			want: SetOption{Name: "Clear Hash"},
+		},
+		{
+			name:    "missing name",
+			text:    "setoption value 32",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got SetOption
+			err := got.UnmarshalText([]byte(test.text))
+			gotErr := (err != nil)
+
+			if got != test.want {
+				t.Errorf("SetOption.UnmarshalText(%q): got %#v, want %#v", test.text, got, test.want)
+			}
+			if gotErr != test.wantErr {
+				t.Errorf("SetOption.UnmarshalText(%q): gotErr %v, wantErr %v", test.text, gotErr, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegister_UnmarshalText(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    Register
+		wantErr bool
+	}{
+		{
+			name: "name and code",
+			text: "register name Stefan MK code 4359874324",
+			want: Register{Name: "Stefan MK", Code: "4359874324"},
+		},
+		{
+			name: "code with spaces",
+			text: "register name Stefan code 4359 8743 24",
+			want: Register{Name: "Stefan", Code: "4359 8743 24"},
+		},
+		{
+			name: "later",
+			text: "register later",
+			want: Register{Later: true},
+		},
+		{
+			name:    "missing code",
+			text:    "register name Stefan MK",
+			wantErr: true,
+		},
+		{
+			name:    "missing name",
+			text:    "register code 4359874324",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got Register
+			err := got.UnmarshalText([]byte(test.text))
+			gotErr := (err != nil)
+
+			if got != test.want {
+				t.Errorf("Register.UnmarshalText(%q): got %#v, want %#v", test.text, got, test.want)
+			}
+			if gotErr != test.wantErr {
+				t.Errorf("Register.UnmarshalText(%q): gotErr %v, wantErr %v", test.text, gotErr, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+
+			// The message round-trips.
+			b, err := got.AppendText(nil)
+			if err != nil || string(b) != test.text {
+				t.Errorf("%#v.AppendText(nil): got %q, %v, want %q", got, b, err, test.text)
+			}
+		})
+	}
+}